@@ -0,0 +1,388 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"went-plate/internal/inflect"
+	"went-plate/internal/manifest"
+)
+
+// artifact is a file created by a generator, together with the identifier
+// other code uses to refer to it
+type artifact struct {
+	Kind   string   // e.g. "Controller"
+	Root   string   // generator directory, e.g. "app/controllers"
+	Path   string   // e.g. "app/controllers/admin/UserController.go"
+	Pkg    string   // package directory, e.g. "app/controllers/admin"
+	Idents []string // referenced identifiers, e.g. "NewUserController"
+	Struct bool     // referenced as &pkg.Ident{} (models) rather than pkg.Ident(...)
+	Table  string   // table of a model, e.g. "users"
+}
+
+// DestroyCommands handles all destroy: commands for removing generated files
+func DestroyCommands() {
+	if len(os.Args) < 2 {
+		fmt.Println("Usage: went destroy:[model|controller|middleware|service|policy|resource] [Namespace/]<name>")
+		return
+	}
+
+	command := os.Args[1]
+	destroyCmd := flag.NewFlagSet(command, flag.ExitOnError)
+	force := destroyCmd.Bool("force", false, "Delete files even if they were modified after generation")
+	args := parseInterspersed(destroyCmd, os.Args[2:])
+
+	if len(args) < 1 {
+		fmt.Printf("Usage: went %s <name> [--force]\n", command)
+		fmt.Printf("Example: went %s User\n", command)
+		return
+	}
+	name := parseName(args[0])
+
+	var artifacts []artifact
+	switch command {
+	case "destroy:model":
+		artifacts = []artifact{modelArtifact(name)}
+	case "destroy:controller":
+		artifacts = []artifact{controllerArtifact(name)}
+	case "destroy:middleware":
+		artifacts = []artifact{middlewareArtifact(name)}
+	case "destroy:service":
+		artifacts = []artifact{serviceArtifact(name)}
+	case "destroy:policy":
+		artifacts = []artifact{policyArtifact(name)}
+	case "destroy:resource":
+		// Everything make:model, make:controller, make:service and make:policy
+		// may have created for the name; missing files are skipped
+		for _, a := range []artifact{controllerArtifact(name), policyArtifact(name), serviceArtifact(name), modelArtifact(name)} {
+			if fileExists(a.Path) {
+				artifacts = append(artifacts, a)
+			}
+		}
+		if len(artifacts) == 0 {
+			fmt.Printf("%s[ERROR]%s Nothing to destroy: no generated files found for '%s'\n", red, reset, name)
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("%s[ERROR]%s Unknown destroy command: %s\n", red, reset, command)
+		fmt.Println("Available commands: destroy:model, destroy:controller, destroy:middleware, destroy:service, destroy:policy, destroy:resource")
+		return
+	}
+
+	if err := DestroyArtifacts(artifacts, *force); err != nil {
+		fmt.Printf("%s[ERROR]%s %v\n", red, reset, err)
+		os.Exit(1)
+	}
+}
+
+// newArtifact describes the file a generator writes below root for n
+func newArtifact(kind, root, suffix string, n generatorName, idents ...string) artifact {
+	return artifact{Kind: kind, Root: root, Path: n.Path(root, suffix), Pkg: path.Join(root, n.Dir()), Idents: idents}
+}
+
+// modelArtifact describes the file created by make:model
+func modelArtifact(n generatorName) artifact {
+	a := newArtifact("Model", "app/models", ".go", n, n.Name)
+	a.Struct = true
+	a.Table = inflect.Table(n.Name)
+	if m, _ := existingModel(n); m != nil && m.TableName != "" {
+		a.Table = m.TableName
+	}
+	return a
+}
+
+// controllerArtifact describes the file created by make:controller
+func controllerArtifact(n generatorName) artifact {
+	return newArtifact("Controller", "app/controllers", "Controller.go", n, "New"+n.Name+"Controller")
+}
+
+// middlewareArtifact describes the file created by make:middleware
+func middlewareArtifact(n generatorName) artifact {
+	return newArtifact("Middleware", "app/middleware", ".go", n, n.Name+"Middleware", n.Name+"Logger")
+}
+
+// serviceArtifact describes the file created by make:service
+func serviceArtifact(n generatorName) artifact {
+	return newArtifact("Service", "app/services", "Service.go", n, "New"+n.Name+"Service")
+}
+
+// policyArtifact describes the file created by make:policy
+func policyArtifact(n generatorName) artifact {
+	return newArtifact("Policy", "app/policies", "Policy.go", n, "New"+n.Name+"Policy")
+}
+
+// DestroyArtifacts deletes generated files and removes the registrations that
+// refer to them, along with the .orig backups of --force. Files that are
+// customized or not recorded in the manifest are only deleted with force;
+// nothing is deleted when any of them is refused. Migrations of destroyed
+// models are kept and listed.
+func DestroyArtifacts(artifacts []artifact, force bool) error {
+	var refused []string
+	for _, a := range artifacts {
+		if !fileExists(a.Path) {
+			return fmt.Errorf("%s %s does not exist", strings.ToLower(a.Kind), a.Path)
+		}
+		if force {
+			continue
+		}
+		switch state, recorded := generatedState(a.Path); {
+		case !recorded:
+			refused = append(refused, a.Path+" (not recorded in "+manifest.Path+")")
+		case state == manifest.Customized:
+			refused = append(refused, a.Path+" (modified since generation)")
+		}
+	}
+	if len(refused) > 0 {
+		for _, r := range refused {
+			fmt.Printf("%s[WARN]%s Refusing to delete %s\n", yellow, reset, r)
+		}
+		return fmt.Errorf("nothing was deleted; use --force to delete them anyway")
+	}
+
+	config, err := readProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	deleted := map[string]bool{}
+	for _, a := range artifacts {
+		if err := removeFile(a.Path); err != nil {
+			return fmt.Errorf("failed to delete %s: %v", a.Path, err)
+		}
+		deleted[a.Path] = true
+		backup := removeBackup(a.Path)
+		if a.Struct {
+			removeModelValidator(a, deleted)
+		}
+		if !DryRun {
+			forgetGenerated(a.Path)
+			removeEmptyDirs(filepath.Dir(a.Path), a.Root)
+			removeEmptyDirs(filepath.Dir(basePath(a.Path)), basePath(a.Root))
+			fmt.Printf("%s[OK]%s %s '%s' deleted%s\n", green, reset, a.Kind, a.Path, backup)
+		}
+		if a.Struct {
+			reportMigrations(a.Table)
+		}
+	}
+
+	return removeReferences(config.ProjectName, artifacts)
+}
+
+// removeModelValidator deletes the validator.go generated next to a model
+// once no other file of its package remains, unless it was customized
+func removeModelValidator(a artifact, deleted map[string]bool) {
+	validatorPath := path.Join(a.Pkg, "validator.go")
+	if !fileExists(validatorPath) {
+		return
+	}
+	files, _ := filepath.Glob(filepath.Join(a.Pkg, "*.go"))
+	for _, file := range files {
+		if file != validatorPath && !deleted[file] {
+			return
+		}
+	}
+	if state, recorded := generatedState(validatorPath); !recorded || state != manifest.Pristine {
+		return
+	}
+
+	if removeFile(validatorPath) != nil {
+		return
+	}
+	backup := removeBackup(validatorPath)
+	if DryRun {
+		return
+	}
+	forgetGenerated(validatorPath)
+	removeEmptyDirs(a.Pkg, a.Root)
+	removeEmptyDirs(basePath(a.Pkg), basePath(a.Root))
+	fmt.Printf("%s[OK]%s Validator '%s' deleted%s\n", green, reset, validatorPath, backup)
+}
+
+// removeBackup deletes the .orig backup --force left next to path and
+// returns a note for the deletion message, empty when there was none
+func removeBackup(path string) string {
+	backupPath := path + ".orig"
+	if !fileExists(backupPath) || removeFile(backupPath) != nil {
+		return ""
+	}
+	return " with its backup " + backupPath
+}
+
+// migrationsDir holds the migrations of a project
+const migrationsDir = "app/migrations"
+
+// reportMigrations lists the migrations whose file name mentions table. They
+// are kept: the database may have run them already, so dropping the table
+// takes a new migration.
+func reportMigrations(table string) {
+	entries, err := os.ReadDir(migrationsDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		words := "_" + strings.NewReplacer(".", "_", "-", "_").Replace(entry.Name()) + "_"
+		if !entry.IsDir() && strings.Contains(words, "_"+table+"_") {
+			fmt.Printf("%s[INFO]%s Kept migration %s; add a migration dropping %s if the table should go too\n", blue, reset, path.Join(migrationsDir, entry.Name()), table)
+		}
+	}
+}
+
+// removeFile deletes path; in dry-run mode it only reports the deletion
+func removeFile(path string) error {
+	if DryRun {
+		fmt.Printf("%s[DRY-RUN]%s would delete: %s\n", cyan, reset, path)
+		return nil
+	}
+	return os.Remove(path)
+}
+
+// removeEmptyDirs removes dir and its parents while they are empty, stopping
+// at the generator root (e.g. app/controllers)
+func removeEmptyDirs(dir, root string) {
+	for dir != root && strings.HasPrefix(dir, root+"/") {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// removeReferences undoes registrations of the destroyed artifacts in the
+// project's Go files: AutoMigrate arguments and single-statement registrations
+// such as r.Mount("/users", controllers.NewUserController(db).Routes()) are
+// removed; any other remaining reference is reported
+func removeReferences(projectName string, artifacts []artifact) error {
+	deleted := map[string]bool{}
+	for _, a := range artifacts {
+		deleted[filepath.Clean(a.Path)] = true
+	}
+
+	return filepath.WalkDir(".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".went", ".git", "pkg", "vendor", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || deleted[filepath.Clean(p)] {
+			return nil
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return nil
+		}
+		aliases := importAliases(p, content)
+
+		lines := strings.Split(string(content), "\n")
+		var kept []string
+		var removed int
+		leftovers := map[string][]string{}
+		for i, line := range lines {
+			for _, a := range artifacts {
+				alias, ok := aliases[importPath(projectName, a.Pkg)]
+				if !ok {
+					continue
+				}
+				for _, ident := range a.Idents {
+					ref := alias + "." + ident
+					var handled bool
+					if line, handled = removeReference(line, ref, a.Struct); handled {
+						removed++
+					} else if regexp.MustCompile(`\b` + regexp.QuoteMeta(ref) + `\b`).MatchString(line) {
+						leftovers[ref] = append(leftovers[ref], strconv.Itoa(i+1))
+					}
+				}
+			}
+			if line != "\x00" {
+				kept = append(kept, line)
+			}
+		}
+
+		for ref, at := range leftovers {
+			fmt.Printf("%s[WARN]%s %s still refers to %s (line %s); remove it manually\n", yellow, reset, p, ref, strings.Join(at, ", "))
+		}
+		if removed == 0 {
+			return nil
+		}
+		var pkgs []string
+		for _, a := range artifacts {
+			pkgs = append(pkgs, importPath(projectName, a.Pkg))
+		}
+		updated := pruneImports(p, []byte(strings.Join(kept, "\n")), pkgs)
+		if err := writeFile(p, updated); err != nil {
+			return fmt.Errorf("failed to update %s: %v", p, err)
+		}
+		if !DryRun {
+			fmt.Printf("%s[OK]%s Removed %d registration(s) from %s\n", green, reset, removed, p)
+		}
+		return nil
+	})
+}
+
+// removeReference strips a qualified reference (e.g. "models.User") from a
+// single line. It returns "\x00" when the whole line should be dropped, and
+// whether the line was changed.
+func removeReference(line, ref string, isStruct bool) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	qualified := regexp.QuoteMeta(ref)
+
+	if isStruct {
+		// db.AutoMigrate(&models.User{}, &models.Post{}) or one model per line
+		model := `&` + qualified + `\{\}`
+		if regexp.MustCompile(`^` + model + `,?$`).MatchString(trimmed) {
+			return "\x00", true
+		}
+		if !strings.Contains(line, "AutoMigrate(") {
+			return line, false
+		}
+		for _, p := range []string{model + `,\s*`, `,\s*` + model, model} {
+			re := regexp.MustCompile(p)
+			if re.MatchString(line) {
+				return re.ReplaceAllString(line, ""), true
+			}
+		}
+		return line, false
+	}
+
+	// A standalone call statement that only registers the artifact
+	standalone := regexp.MustCompile(`^[\w.]+\(.*\b` + qualified + `\(.*\)$`)
+	if standalone.MatchString(trimmed) && !strings.Contains(trimmed, "=") {
+		return "\x00", true
+	}
+	return line, false
+}
+
+// importAliases maps the import paths of a Go file to the names they are used by
+func importAliases(filename string, content []byte) map[string]string {
+	aliases := map[string]string{}
+	file, err := parser.ParseFile(token.NewFileSet(), filename, content, parser.ImportsOnly)
+	if err != nil {
+		return aliases
+	}
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		alias := path.Base(importPath)
+		if imp.Name != nil {
+			alias = imp.Name.Name
+		}
+		aliases[importPath] = alias
+	}
+	return aliases
+}
@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"went-plate/internal/embedded"
+	"went-plate/internal/gomod"
+)
+
+// formatGoSource drops unused imports from generated Go code and formats it
+// with go/format. Output that is not valid Go is reported with the template
+// it was rendered from and the offending line.
+func formatGoSource(templateName, outputPath string, content []byte) ([]byte, error) {
+	pruned := pruneImports(outputPath, content, nil)
+
+	formatted, err := format.Source(pruned)
+	if err != nil {
+		return nil, generatedSyntaxError(templateName, outputPath, pruned, err)
+	}
+	return formatted, nil
+}
+
+// generatedSyntaxError describes a syntax error in rendered template output,
+// quoting the lines around it
+func generatedSyntaxError(templateName, outputPath string, content []byte, err error) error {
+	if _, source, _ := embedded.ResolveTemplate(templateName); source != "" && source != embedded.Embedded && source != templateName {
+		templateName += " (" + source + ")"
+	}
+
+	list, ok := err.(scanner.ErrorList)
+	if !ok || len(list) == 0 {
+		return fmt.Errorf("template %s rendered invalid Go for %s: %v", templateName, outputPath, err)
+	}
+
+	first := list[0]
+	lines := strings.Split(string(content), "\n")
+	var excerpt strings.Builder
+	for i := max(first.Pos.Line-3, 1); i <= min(first.Pos.Line+1, len(lines)); i++ {
+		marker := "  "
+		if i == first.Pos.Line {
+			marker = "> "
+		}
+		fmt.Fprintf(&excerpt, "\n  %s%4d | %s", marker, i, lines[i-1])
+	}
+	return fmt.Errorf("template %s rendered invalid Go for %s at line %d:%d: %s%s",
+		templateName, outputPath, first.Pos.Line, first.Pos.Column, first.Msg, excerpt.String())
+}
+
+// pruneImports drops imports that are no longer referenced in the file, so
+// generated code and files edited by destroy keep compiling. Imports whose
+// package name isn't known for sure are kept. When pkgs is not nil only
+// imports of those packages, generated by went and named after their
+// directory, are considered.
+func pruneImports(filename string, content []byte, pkgs []string) []byte {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, content, 0)
+	if err != nil {
+		return content
+	}
+
+	used := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	module, _ := gomod.Find(filepath.Dir(filename))
+	drop := map[int]bool{}
+	for _, imp := range file.Imports {
+		importPath, _ := strconv.Unquote(imp.Path.Value)
+		if pkgs != nil && !containsString(pkgs, importPath) {
+			continue
+		}
+		var name string
+		var known bool
+		switch {
+		case imp.Name != nil:
+			name, known = imp.Name.Name, true
+		case pkgs != nil:
+			name, known = path.Base(importPath), true
+		default:
+			name, known = importName(importPath, module)
+		}
+		if !known || name == "_" || name == "." || used[name] {
+			continue
+		}
+		drop[fset.Position(imp.Pos()).Line] = true
+	}
+	if len(drop) == 0 {
+		return content
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var kept []string
+	for i, line := range lines {
+		if drop[i+1] {
+			trimmed := strings.TrimSpace(line)
+			// Only lines holding nothing but the import spec are removed
+			if !strings.HasPrefix(trimmed, "import") || !strings.Contains(trimmed, "(") {
+				continue
+			}
+		}
+		kept = append(kept, line)
+	}
+	return []byte(strings.Join(kept, "\n"))
+}
+
+// knownPackages names the third-party packages imported by the stock templates
+var knownPackages = map[string]string{
+	"github.com/gin-gonic/gin":               "gin",
+	"github.com/go-chi/chi/v5":               "chi",
+	"github.com/go-playground/validator/v10": "validator",
+	"github.com/google/uuid":                 "uuid",
+	"github.com/oklog/ulid/v2":               "ulid",
+	"gorm.io/gorm":                           "gorm",
+	"gorm.io/gorm/clause":                    "clause",
+	"gorm.io/gorm/schema":                    "schema",
+}
+
+// stdVersion matches the version suffix of standard library paths ("math/rand/v2")
+var stdVersion = regexp.MustCompile(`/v[0-9]+$`)
+
+// importName returns the name of the package an import path refers to and
+// whether it is known for sure: packages of the stock templates, of the
+// standard library, which are named after their directory, and of the module
+// whose package clause can be read. Any other package may be named anything
+// ("k8s.io/api/core/v1" is v1, "gopkg.in/yaml.v3" is yaml), so it isn't known.
+func importName(importPath string, module *gomod.Module) (string, bool) {
+	if name, ok := knownPackages[importPath]; ok {
+		return name, true
+	}
+	if module != nil && (importPath == module.Path || strings.HasPrefix(importPath, module.Path+"/")) {
+		rel := strings.TrimPrefix(strings.TrimPrefix(importPath, module.Path), "/")
+		return packageClause(filepath.Join(module.Dir, filepath.FromSlash(rel)))
+	}
+	if first, _, _ := strings.Cut(importPath, "/"); strings.Contains(first, ".") {
+		return "", false
+	}
+	return path.Base(stdVersion.ReplaceAllString(importPath, "")), true
+}
+
+// packageClause returns the package name declared by the Go files in dir
+func packageClause(dir string) (string, bool) {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), f, nil, parser.PackageClauseOnly)
+		if err == nil {
+			return file.Name.Name, true
+		}
+	}
+	return "", false
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -2,97 +2,284 @@ package commands
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
 )
 
 // MakeCommands handles all make: commands for generating files
 func MakeCommands() {
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: went make:[model|controller|middleware|service] <name>")
+		fmt.Println("Usage: went make:[model|controller|middleware|service|policy] [Namespace/]<name>")
 		return
 	}
 
 	command := os.Args[1]
 
+	// Flags shared by every generator; they may follow the name
+	makeCmd := flag.NewFlagSet(command, flag.ExitOnError)
+	addOverwriteFlags(makeCmd)
+	routerFlag := makeCmd.String("router", "", "Router (gin|chi) - default: .env ROUTER")
+	makeCmd.StringVar(&tableOverride, "table", "", "Table name (default: snake_case plural of the model)")
+	makeCmd.StringVar(&fieldsFlag, "fields", "", `Model fields, e.g. "title:string:required price:decimal" (default: name, description)`)
+	makeCmd.StringVar(&pkFlag, "pk", "", "Primary key type (int|uuid|ulid) - default: wentconfig.json primary_key, or int")
+	makeCmd.StringVar(&paginationFlag, "pagination", "", "List pagination (offset|cursor) - default: wentconfig.json pagination, or offset")
+	makeCmd.BoolVar(&versionedFlag, "versioned", false, "Add a version column checked on update, exposed as ETag (make:model)")
+	makeCmd.StringVar(&belongsToFlag, "belongs-to", "", `Belongs-to associations, e.g. "author:User,Category"`)
+	makeCmd.StringVar(&hasManyFlag, "has-many", "", `Has-many associations with nested routes, e.g. "Comment"`)
+	makeCmd.StringVar(&manyToManyFlag, "many-to-many", "", `Many-to-many associations, e.g. "Tag"`)
+	args := parseInterspersed(makeCmd, os.Args[2:])
+
 	switch command {
 	case "make:model":
-		if len(os.Args) < 3 {
-			fmt.Println("Usage: went make:model <ModelName>")
-			fmt.Println("Example: went make:model User")
+		if len(args) < 1 {
+			fmt.Println("Usage: went make:model <ModelName> [--fields \"name:type[:validate] ...\"] [--pk int|uuid|ulid] [--pagination offset|cursor] [--versioned]")
+			fmt.Println("       [--belongs-to [name:]Model,...] [--has-many [name:]Model,...] [--many-to-many [name:]Model,...]")
+			fmt.Println("Example: went make:model Product --fields \"title:string:required,max=120 price:decimal\"")
 			return
 		}
-		modelName := capitalizeFirst(os.Args[2])
-		CreateFileFromTemplate("internal/templates/model.tpl", "app/models/"+modelName+".go", modelName)
-		fmt.Printf("%s[OK]%s Model '%s' created successfully!\n", green, reset, modelName)
+		model := parseName(args[0])
+		if CreateFileFromTemplate("internal/templates/model.tpl", model.Path("app/models", ".go"), model.String()) == fileWritten {
+			fmt.Printf("%s[OK]%s Model '%s' created successfully!\n", green, reset, model)
+		}
+		ensureModelValidator(model)
+		if m, _ := existingModel(model); m != nil && m.Keyset {
+			ensureQueryPackage(model)
+		}
 
 	case "make:controller":
-		if len(os.Args) < 3 {
-			fmt.Println("Usage: went make:controller <ControllerName>")
+		if len(args) < 1 {
+			fmt.Println("Usage: went make:controller <ControllerName> [--pagination offset|cursor]")
 			fmt.Println("Example: went make:controller User")
 			return
 		}
-		controllerName := capitalizeFirst(os.Args[2])
-
-		// Read router preference from .env file
-		router := getRouterFromEnv()
-		switch router {
-		case "gin":
-			CreateFileFromTemplate("internal/templates/controller_gin.tpl", "app/controllers/"+controllerName+"Controller.go", controllerName)
-		case "chi":
-			CreateFileFromTemplate("internal/templates/controller_chi.tpl", "app/controllers/"+controllerName+"Controller.go", controllerName)
-		default:
-			fmt.Printf("%s[ERROR]%s Invalid ROUTER definition in .env file: '%s'\n", red, reset, router)
-			fmt.Println("Valid options are: 'gin' or 'chi'")
-			fmt.Println("If no .env file exists or ROUTER is empty, 'gin' will be used as default")
+		controller := parseName(args[0])
+
+		router, ok := generatorRouter(*routerFlag)
+		if !ok {
 			return
 		}
-
-		fmt.Printf("%s[OK]%s Controller '%s' created successfully using %s router!\n", green, reset, controllerName+"Controller", router)
+		if CreateFileFromTemplate("internal/templates/controller_"+router+".tpl", controller.Path("app/controllers", "Controller.go"), controller.String()) == fileWritten {
+			fmt.Printf("%s[OK]%s Controller '%s' created successfully using %s router!\n", green, reset, controller.String()+"Controller", router)
+		}
+		ensureQueryPackage(controller)
+		if err := ensureErrorPackage(); err != nil {
+			fmt.Printf("%s[ERROR]%s %s\n", red, reset, err.Error())
+		}
+		if err := ensurePatchPackage(); err != nil {
+			fmt.Printf("%s[ERROR]%s %s\n", red, reset, err.Error())
+		}
+		ensureBatchPackage(controller)
+		ensureTransferPackage(controller)
 
 	case "make:middleware":
-		if len(os.Args) < 3 {
+		if len(args) < 1 {
 			fmt.Println("Usage: went make:middleware <MiddlewareName>")
 			fmt.Println("Example: went make:middleware Auth")
 			return
 		}
-		middlewareName := capitalizeFirst(os.Args[2])
-		CreateFileFromTemplate("internal/templates/middleware.tpl", "app/middleware/"+middlewareName+".go", middlewareName)
-		fmt.Printf("%s[OK]%s Middleware '%s' created successfully!\n", green, reset, middlewareName)
+		middleware := parseName(args[0])
+		router, ok := generatorRouter(*routerFlag)
+		if !ok {
+			return
+		}
+		if CreateFileFromTemplate("internal/templates/middleware_"+router+".tpl", middleware.Path("app/middleware", ".go"), middleware.String()) == fileWritten {
+			fmt.Printf("%s[OK]%s Middleware '%s' created successfully using %s router!\n", green, reset, middleware, router)
+		}
 
 	case "make:service":
-		if len(os.Args) < 3 {
+		if len(args) < 1 {
 			fmt.Println("Usage: went make:service <ServiceName>")
 			fmt.Println("Example: went make:service User")
 			return
 		}
-		serviceName := capitalizeFirst(os.Args[2])
-		CreateFileFromTemplate("internal/templates/service.tpl", "app/services/"+serviceName+"Service.go", serviceName)
-		fmt.Printf("%s[OK]%s Service '%s' created successfully!\n", green, reset, serviceName+"Service")
+		service := parseName(args[0])
+		if CreateFileFromTemplate("internal/templates/service.tpl", service.Path("app/services", "Service.go"), service.String()) == fileWritten {
+			fmt.Printf("%s[OK]%s Service '%s' created successfully!\n", green, reset, service.String()+"Service")
+		}
+
+	case "make:policy":
+		if len(args) < 1 {
+			fmt.Println("Usage: went make:policy <ModelName>")
+			fmt.Println("Example: went make:policy Post")
+			return
+		}
+		policy := parseName(args[0])
+		outcome := CreateFileFromTemplate("internal/templates/policy.tpl", policy.Path("app/policies", "Policy.go"), policy.String())
+		if outcome == fileSkipped {
+			return
+		}
+		if outcome == fileWritten {
+			fmt.Printf("%s[OK]%s Policy '%s' created successfully!\n", green, reset, policy.String()+"Policy")
+		}
+
+		if fileExists(policy.Path("app/controllers", "Controller.go")) {
+			fmt.Printf("%s[INFO]%s %sController already exists; assign the policy with:\n", blue, reset, policy)
+			fmt.Printf("  controller.Policy = policies.New%sPolicy()\n", policy.Name)
+		}
+
+	case "make:from-openapi":
+		router := routerFlag
+		if len(args) < 1 {
+			fmt.Println("Usage: went make:from-openapi <spec.yaml> [--router gin|chi]")
+			fmt.Println("Example: went make:from-openapi openapi.yaml")
+			return
+		}
+
+		if *router == "" {
+			*router = getRouterFromEnv()
+		}
+		*router = strings.ToLower(*router)
+		if *router != "gin" && *router != "chi" {
+			fmt.Printf("%s[ERROR]%s Invalid router: '%s'\n", red, reset, *router)
+			fmt.Println("Valid options are: 'gin' or 'chi'")
+			return
+		}
+
+		if err := MakeFromOpenAPI(args[0], *router); err != nil {
+			fmt.Printf("%s[ERROR]%s %v\n", red, reset, err)
+			os.Exit(1)
+		}
 
 	case "make:migration":
-		if len(os.Args) < 3 {
+		if len(args) < 1 {
 			fmt.Println("Usage: went make:migration <migration_name>")
 			fmt.Println("Example: went make:migration create_users_table")
 			return
 		}
-		migrationName := strings.ToLower(os.Args[2])
+		migrationName := strings.ToLower(args[0])
 		CreateMigrationFile(migrationName)
 		fmt.Printf("%s[OK]%s Migration '%s' created successfully!\n", green, reset, migrationName)
 
 	default:
+		// Generators declared in wentconfig.json
+		if generator, ok := findGenerator(strings.TrimPrefix(command, "make:")); ok {
+			if err := RunGenerator(generator, args); err != nil {
+				fmt.Printf("%s[ERROR]%s %v\n", red, reset, err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		fmt.Printf("%s[ERROR]%s Unknown make command: %s\n", red, reset, command)
-		fmt.Println("Available commands: make:model, make:controller, make:middleware, make:service, make:migration")
+		available := "make:model, make:controller, make:middleware, make:service, make:policy, make:from-openapi, make:migration"
+		for _, g := range customGenerators() {
+			available += ", make:" + g.Name
+		}
+		fmt.Println("Available commands: " + available)
 	}
 }
 
-// capitalizeFirst capitalizes the first letter of a string
-func capitalizeFirst(s string) string {
-	if len(s) == 0 {
-		return s
+// ensureModelValidator creates the validator shared by the models of n's
+// package, unless a file of the package already declares it
+func ensureModelValidator(n generatorName) {
+	dir := path.Join("app/models", n.Dir())
+	if packageDeclares(dir, "validate") {
+		return
+	}
+	CreateFileFromTemplate("internal/templates/model_validator.tpl", path.Join(dir, "validator.go"), n.String())
+}
+
+// ensureQueryPackage creates the sort and filter helpers of the generated
+// controllers, unless the project already has them
+func ensureQueryPackage(n generatorName) {
+	if !packageDeclares("app/query", "Filter") {
+		CreateFileFromTemplate("internal/templates/query.tpl", "app/query/query.go", n.String())
+	}
+	if !packageDeclares("app/query", "NewKeyset") {
+		CreateFileFromTemplate("internal/templates/keyset.tpl", "app/query/keyset.go", n.String())
+	}
+}
+
+// ensureErrorPackage creates the problem+json error responses of the
+// generated controllers, unless the project already has them
+func ensureErrorPackage() error {
+	if !packageDeclares("app/httperr", "Write") {
+		if err := writeStub("httperr", "app/httperr/httperr.go", nil); err != nil {
+			return err
+		}
+	}
+	if !packageDeclares("app/httperr", "ValidationMessages") {
+		return writeStub("httperr_validation", "app/httperr/validation.go", nil)
+	}
+	return nil
+}
+
+// ensureBatchPackage creates the per-item results of the generated batch
+// handlers, unless the project already has them
+func ensureBatchPackage(n generatorName) {
+	if !packageDeclares("app/batch", "Failed") {
+		CreateFileFromTemplate("internal/templates/batch.tpl", "app/batch/batch.go", n.String())
+	}
+}
+
+// ensureTransferPackage creates the CSV and JSON Lines support of the
+// generated export and import handlers, unless the project already has it
+func ensureTransferPackage(n generatorName) {
+	if !packageDeclares("app/transfer", "NewReader") {
+		CreateFileFromTemplate("internal/templates/transfer.tpl", "app/transfer/transfer.go", n.String())
+	}
+}
+
+// ensurePatchPackage creates the merge patch and JSON Patch support of the
+// generated PATCH handlers, unless the project already has it
+func ensurePatchPackage() error {
+	if packageDeclares("app/patch", "Apply") {
+		return nil
+	}
+	return writeStub("patch", "app/patch/patch.go", nil)
+}
+
+// packageDeclares reports whether a Go file in dir declares name at package level
+func packageDeclares(dir, name string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil && d.Name.Name == name {
+					return true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if vs, ok := spec.(*ast.ValueSpec); ok {
+						for _, ident := range vs.Names {
+							if ident.Name == name {
+								return true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// generatorRouter returns the router generated code targets: the --router
+// flag when given, otherwise the .env ROUTER value. Invalid values are reported.
+func generatorRouter(flagValue string) (string, bool) {
+	router := getRouterFromEnv()
+	if flagValue != "" {
+		router = strings.ToLower(flagValue)
+	}
+	if router != "gin" && router != "chi" {
+		fmt.Printf("%s[ERROR]%s Invalid ROUTER definition in .env file: '%s'\n", red, reset, router)
+		fmt.Println("Valid options are: 'gin' or 'chi'")
+		fmt.Println("If no .env file exists or ROUTER is empty, 'gin' will be used as default")
+		return "", false
 	}
-	return strings.ToUpper(s[:1]) + s[1:]
+	return router, true
 }
 
 // getRouterFromEnv reads the ROUTER value from .env file
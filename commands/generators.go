@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// GeneratorConfig declares a custom make: command in wentconfig.json, e.g.
+//
+//	{"name": "dto", "template": "dto", "output": "app/dto/{{.ModelName}}DTO.go"}
+type GeneratorConfig struct {
+	Name        string   `json:"name"`                  // command name, "dto" for make:dto
+	Description string   `json:"description,omitempty"` // shown by went help
+	Template    string   `json:"template"`              // template name ("dto") or project path ("generators/dto.tpl")
+	Output      string   `json:"output"`                // output path pattern, rendered like a template
+	Args        []string `json:"args,omitempty"`        // required arguments after the name, available as .Args.<name>
+	Hooks       []string `json:"hooks,omitempty"`       // commands run after the file is written, e.g. "git add {{.Output}}"
+}
+
+// builtinGenerators are the make: commands custom generators may not replace
+var builtinGenerators = map[string]bool{
+	"model": true, "controller": true, "middleware": true, "service": true,
+	"policy": true, "from-openapi": true, "migration": true,
+}
+
+// Usage returns the command line of the generator
+func (g GeneratorConfig) Usage() string {
+	usage := "make:" + g.Name + " <name>"
+	for _, arg := range g.Args {
+		usage += " <" + arg + ">"
+	}
+	return usage
+}
+
+// validate reports configuration mistakes in a generator declaration
+func (g GeneratorConfig) validate() error {
+	switch {
+	case g.Template == "":
+		return fmt.Errorf("custom generator '%s' has no template", g.Name)
+	case g.Output == "":
+		return fmt.Errorf("custom generator '%s' has no output path", g.Name)
+	}
+	return nil
+}
+
+// customGenerators returns the generators declared in wentconfig.json, if
+// any; declarations without a name or replacing a built-in are ignored
+func customGenerators() []GeneratorConfig {
+	config, err := readProjectConfig()
+	if err != nil {
+		return nil
+	}
+	var generators []GeneratorConfig
+	for _, g := range config.Generators {
+		if g.Name != "" && !builtinGenerators[g.Name] {
+			generators = append(generators, g)
+		}
+	}
+	return generators
+}
+
+// findGenerator looks up a custom generator by its command name
+func findGenerator(name string) (GeneratorConfig, bool) {
+	for _, g := range customGenerators() {
+		if g.Name == name {
+			return g, true
+		}
+	}
+	return GeneratorConfig{}, false
+}
+
+// RunGenerator renders a custom generator's template for args (the name
+// followed by the generator's declared arguments) and runs its hooks
+func RunGenerator(g GeneratorConfig, args []string) error {
+	if err := g.validate(); err != nil {
+		return err
+	}
+	if len(args) < 1+len(g.Args) {
+		return fmt.Errorf("usage: went %s", g.Usage())
+	}
+
+	data, err := newTemplateData(args[0])
+	if err != nil {
+		return err
+	}
+	data.Args = map[string]string{}
+	for i, arg := range g.Args {
+		data.Args[arg] = args[i+1]
+	}
+
+	output, err := renderString("output of "+g.Name, g.Output, data)
+	if err != nil {
+		return err
+	}
+	output = filepath.Clean(output)
+	if filepath.IsAbs(output) || output == "." || strings.HasPrefix(output, "..") {
+		return fmt.Errorf("generator '%s' renders output path '%s' outside the project", g.Name, output)
+	}
+
+	content, err := renderGenerated(g.Template, output, data)
+	if err != nil {
+		return err
+	}
+	outcome := writeGeneratedFile(output, g.Template, content)
+	if outcome == fileSkipped {
+		return nil
+	}
+	if outcome == fileWritten && !DryRun {
+		fmt.Printf("%s[OK]%s Created %s\n", green, reset, output)
+	}
+
+	hookData := struct {
+		*TemplateData
+		Output string
+	}{data, output}
+	for _, hook := range g.Hooks {
+		command, err := renderString("hook of "+g.Name, hook, hookData)
+		if err != nil {
+			return err
+		}
+		if err := runHook(command); err != nil {
+			return err
+		}
+	}
+
+	// Record what the hooks left behind as generated
+	if formatted, err := os.ReadFile(output); err == nil && len(g.Hooks) > 0 && !DryRun {
+		recordGenerated(output, g.Template, formatted)
+	}
+	return nil
+}
+
+// runHook runs a post-generation command; in dry-run mode it is only reported
+func runHook(command string) error {
+	parts, err := splitWords(command)
+	if err != nil {
+		return fmt.Errorf("invalid hook '%s': %v", command, err)
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	if DryRun {
+		fmt.Printf("%s[DRY-RUN]%s would run: %s\n", cyan, reset, command)
+		return nil
+	}
+
+	fmt.Printf("%s[INFO]%s Running %s\n", blue, reset, command)
+	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("hook '%s' failed: %v", command, err)
+	}
+	return nil
+}
+
+// splitWords splits a command line into arguments like a shell, without
+// expanding anything: single and double quotes group words (`git add
+// "{{.Output}}"`), and outside single quotes a backslash escapes a space, a
+// quote or a backslash
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			// Other backslashes are kept, as in Windows paths
+			if !unicode.IsSpace(r) && !strings.ContainsRune(`"'\`, r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	switch {
+	case escaped:
+		return nil, fmt.Errorf("trailing backslash")
+	case quote != 0:
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// renderString renders a short inline template such as an output path pattern
+func renderString(name, text string, data interface{}) (string, error) {
+	tpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %v", name, err)
+	}
+	return buf.String(), nil
+}
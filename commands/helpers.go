@@ -1,12 +1,15 @@
 package commands
 
 import (
+	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 	"text/template"
 	"went-plate/internal/embedded"
+	"went-plate/internal/inflect"
 )
 
 // ---- Renkler ----
@@ -50,8 +53,8 @@ func PrintBanner() {
 		fmt.Println(cyan + "│ " + reset + bold + white + line + reset + cyan + " │" + reset)
 	}
 	fmt.Println(cyan + bot + reset)
-	fmt.Println(dim + "      A minimal project initializer · " + reset + magenta + Version + reset + "\n")
-	fmt.Println(dim + "      " + reset + magenta + goVersion + reset + "\n")
+	fmt.Print(dim + "      A minimal project initializer · " + reset + magenta + Version + reset + "\n\n")
+	fmt.Print(dim + "      " + reset + magenta + goVersion + reset + "\n\n")
 }
 
 func PrintHelp() {
@@ -82,11 +85,37 @@ func readProjectConfig() (*Config, error) {
 	return &config, nil
 }
 
-// createFileFromTemplate creates a file from a template
-func CreateFileFromTemplate(templatePath, outputPath, modelName string) {
+// tableOverride replaces the conventional table name of generated models (--table)
+var tableOverride string
+
+// CreateFileFromTemplate creates a file from a template and reports what was
+// done with it; nothing is in dry-run mode
+func CreateFileFromTemplate(templatePath, outputPath, modelName string) writeOutcome {
 	// Extract template name from path (e.g., "internal/templates/model.tpl" -> "model")
 	templateName := strings.TrimSuffix(strings.TrimPrefix(templatePath, "internal/templates/"), ".tpl")
 
+	data, err := newTemplateData(modelName)
+	if err != nil {
+		fmt.Printf("%s[ERROR]%s %s\n", red, reset, err.Error())
+		return fileSkipped
+	}
+
+	content, err := renderGenerated(templateName, outputPath, data)
+	if err != nil {
+		fmt.Printf("%s[ERROR]%s %s\n", red, reset, err.Error())
+		return fileSkipped
+	}
+
+	outcome := writeGeneratedFile(outputPath, templateName, content)
+	if DryRun {
+		return fileSkipped
+	}
+	return outcome
+}
+
+// newTemplateData prepares the data generator templates are rendered with;
+// modelName may be namespaced ("admin/User")
+func newTemplateData(modelName string) (*TemplateData, error) {
 	// Read project configuration to get project name
 	config, err := readProjectConfig()
 	if err != nil {
@@ -101,61 +130,109 @@ func CreateFileFromTemplate(templatePath, outputPath, modelName string) {
 		}
 	}
 
-	// Get template content from embedded filesystem
-	templateContent, err := embedded.GetTemplate(templateName)
+	name := parseName(modelName)
+	modelName = name.Name
+
+	tableName := inflect.Table(modelName)
+	if tableOverride != "" {
+		tableName = tableOverride
+	}
+
+	primaryKey, err := templatePrimaryKey(name, config)
 	if err != nil {
-		fmt.Printf("%s[ERROR]%s %s\n", red, reset, err.Error())
-		fmt.Printf("Make sure you're running this command from within a WentPlate project directory\n")
-		fmt.Printf("or that the template files are available.\n")
-		return
+		return nil, err
+	}
+	pagination, err := templatePagination(name, config)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := templateFields(name, primaryKey)
+	if err != nil {
+		return nil, err
+	}
+	relations, err := templateRelations(name)
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse the template content
-	tpl, err := template.New(templateName).Parse(string(templateContent))
+	// Generated search queries use ILIKE unless another database is configured
+	if config.Database == "" {
+		config.Database = "postgres"
+	}
+
+	keys, err := foreignKeyFields(name, relations, fields, primaryKey, config.Database)
 	if err != nil {
-		fmt.Printf("%s[ERROR]%s Failed to parse template %s: %v\n", red, reset, templateName, err)
-		return
+		return nil, err
 	}
+	fields = append(fields, keys...)
 
-	// Create output directory if it doesn't exist
-	os.MkdirAll(GetDir(outputPath), os.ModePerm)
+	// Prepare template data with project information and derived names
+	return &TemplateData{
+		ModelName:      modelName,
+		PluralName:     inflect.Plural(modelName),
+		VarName:        inflect.Variable(modelName),
+		PluralVarName:  inflect.Variable(inflect.Plural(modelName)),
+		TableName:      tableName,
+		RouteName:      name.RoutePrefix() + inflect.Route(modelName),
+		Package:        name.Package(),
+		Namespace:      name.Dir(),
+		ModelsImport:   namespacedImport(config.ProjectName, "app/models", name, ".go"),
+		PoliciesImport: namespacedImport(config.ProjectName, "app/policies", name, "Policy.go"),
+		ProjectName:    config.ProjectName,
+		AppName:        config.ProjectName, // For backward compatibility
+		ModulePath:     projectModulePath(config.ProjectName),
+		HasPolicy:      fileExists(name.Path("app/policies", "Policy.go")) || fileExists("app/policies/"+modelName+"Policy.go"),
+		PrimaryKey:     primaryKey,
+		IDType:         primaryKeyTypes[primaryKey],
+		Pagination:     pagination,
+		Versioned:      templateVersioned(name),
+		Config:         *config,
+		Fields:         fields,
+		Relations:      relations,
+	}, nil
+}
 
-	// Check if file already exists
-	if _, err := os.Stat(outputPath); err == nil {
-		fmt.Printf("Skipped (already exists): %s\n", outputPath)
-		return
+// renderGenerated renders a template for outputPath; Go files are formatted
+// and checked by formatGoSource
+func renderGenerated(templateName, outputPath string, data interface{}) ([]byte, error) {
+	content, err := renderTemplate(templateName, data)
+	if err != nil {
+		return nil, err
 	}
+	if strings.HasSuffix(outputPath, ".go") {
+		return formatGoSource(templateName, outputPath, content)
+	}
+	return content, nil
+}
 
-	// Create the output file
-	f, err := os.Create(outputPath)
+// renderTemplate renders an embedded template with the given data
+func renderTemplate(templateName string, data interface{}) ([]byte, error) {
+	// Get template content from an override or the embedded filesystem
+	templateContent, source, err := embedded.ResolveTemplate(templateName)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	defer f.Close()
 
-	// Prepare template data with project information
-	data := struct {
-		ModelName   string
-		TableName   string
-		ProjectName string
-		AppName     string
-	}{
-		ModelName:   modelName,
-		TableName:   strings.ToLower(modelName) + "s",
-		ProjectName: config.ProjectName,
-		AppName:     config.ProjectName, // For backward compatibility
+	// Overrides are named by their path so errors point at the right file
+	name := templateName
+	if source != embedded.Embedded {
+		name = source
 	}
 
-	// Execute template
-	err = tpl.Execute(f, data)
+	// Parse the template content
+	tpl, err := template.New(name).Funcs(templateFuncs).Parse(string(templateContent))
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to parse template %s: %v", templateName, err)
 	}
 
-	fmt.Printf("%s[OK]%s %s '%s' created successfully!\n", green, reset, strings.Title(templateName), modelName)
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template %s: %v", templateName, err)
+	}
+	return buf.Bytes(), nil
 }
 
-// ListAvailableTemplates shows all available templates embedded in the binary
+// ListAvailableTemplates shows all available templates and where each one is read from
 func ListAvailableTemplates() {
 	fmt.Printf("%sAvailable Templates:%s\n", cyan, reset)
 	templates, err := embedded.ListTemplates()
@@ -165,10 +242,39 @@ func ListAvailableTemplates() {
 	}
 
 	for _, tmpl := range templates {
-		fmt.Printf("  - %s%s%s\n", green, tmpl, reset)
+		_, source, err := embedded.ResolveTemplate(tmpl)
+		if err != nil {
+			continue
+		}
+		if source == embedded.Embedded {
+			fmt.Printf("  - %s%-26s%s %s%s%s\n", green, tmpl, reset, dim, source, reset)
+		} else {
+			fmt.Printf("  - %s%-26s%s %s%s%s\n", yellow, tmpl, reset, cyan, source, reset)
+		}
+	}
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments (e.g. "make:model User --table people") and returns the positionals
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
 	}
 }
 
+// fileExists reports whether a file exists at the given path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
 // getDir gets the directory part of a file path
 func GetDir(path string) string {
 	idx := strings.LastIndex(path, "/")
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"path"
+	"strings"
+
+	"went-plate/internal/gomod"
+	"went-plate/internal/inflect"
+)
+
+// generatorName is a generator argument that may be namespaced with slashes,
+// e.g. "Admin/User" generates into the admin sub-package
+type generatorName struct {
+	Segments []string // namespace segments as typed, e.g. ["Admin"]
+	Name     string   // inflected base name, e.g. "User"
+}
+
+// parseName splits a slash-separated generator argument into its namespace
+// and base name ("Admin/Billing/invoice" -> admin/billing, "Invoice")
+func parseName(arg string) generatorName {
+	var parts []string
+	for _, p := range strings.FieldsFunc(arg, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return generatorName{}
+	}
+	return generatorName{
+		Segments: parts[:len(parts)-1],
+		Name:     inflect.Pascal(parts[len(parts)-1]),
+	}
+}
+
+// Dir returns the sub-directory of the namespace ("admin/billing"), empty for the root package
+func (n generatorName) Dir() string {
+	dirs := make([]string, len(n.Segments))
+	for i, s := range n.Segments {
+		dirs[i] = packageName(s)
+	}
+	return path.Join(dirs...)
+}
+
+// Package returns the Go package name of the namespace, empty for the root package
+func (n generatorName) Package() string {
+	if len(n.Segments) == 0 {
+		return ""
+	}
+	return packageName(n.Segments[len(n.Segments)-1])
+}
+
+// RoutePrefix returns the URL prefix of the namespace ("admin/billing/"),
+// empty for the root package. It mirrors the package directories.
+func (n generatorName) RoutePrefix() string {
+	if len(n.Segments) == 0 {
+		return ""
+	}
+	return n.Dir() + "/"
+}
+
+// Path returns where a file of this name lives below root
+// (e.g. "app/controllers" + "Controller.go" -> "app/controllers/admin/UserController.go")
+func (n generatorName) Path(root, suffix string) string {
+	return path.Join(root, n.Dir(), n.Name+suffix)
+}
+
+// String returns the namespaced name ("admin/User"), as accepted by parseName
+func (n generatorName) String() string {
+	return path.Join(n.Dir(), n.Name)
+}
+
+// packageName converts a namespace segment into a Go package name ("BillingArea" -> "billingarea")
+func packageName(segment string) string {
+	return strings.ReplaceAll(inflect.Snake(segment), "_", "")
+}
+
+// namespacedImport returns the import spec for the package under root that
+// belongs to n: the namespaced sub-package when n.Path(root, suffix) exists, otherwise
+// the root package. Sub-packages are aliased to the root package's name so
+// templates can keep referring to e.g. models.User.
+func namespacedImport(projectName, root string, n generatorName, suffix string) string {
+	alias := path.Base(root)
+	if n.Dir() != "" && fileExists(n.Path(root, suffix)) {
+		return alias + ` "` + importPath(projectName, path.Join(root, n.Dir())) + `"`
+	}
+	return `"` + importPath(projectName, root) + `"`
+}
+
+// importPath returns the import path of the package in dir (relative to the
+// project root, e.g. "app/models/admin"), derived from the nearest go.mod or
+// go.work. Without one, the project name from wentconfig.json is used as the
+// module path.
+func importPath(projectName, dir string) string {
+	if mod, err := gomod.Find(dir); err == nil && mod != nil {
+		if p, err := mod.ImportPath(dir); err == nil {
+			return p
+		}
+	}
+	return path.Join(projectName, dir)
+}
+
+// projectModulePath returns the module path of the project root, falling back
+// to the project name from wentconfig.json
+func projectModulePath(projectName string) string {
+	return importPath(projectName, ".")
+}
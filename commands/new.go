@@ -3,7 +3,6 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -13,9 +12,16 @@ import (
 
 type Config struct {
 	ProjectName string `json:"project_name"`
-	Template    string `json:"template"`   // API | CLI | W/ReactJS
-	Deployment  string `json:"deployment"` // docker | kubernetes | no-deployment
-	Router      string `json:"router"`     // gin | chi
+	Template    string `json:"template"`              // API | CLI | W/ReactJS
+	Deployment  string `json:"deployment"`            // docker | kubernetes | no-deployment
+	Router      string `json:"router"`                // gin | chi
+	Database    string `json:"database,omitempty"`    // postgres | mysql | sqlite - default: postgres
+	PrimaryKey  string `json:"primary_key,omitempty"` // int | uuid | ulid - default: int
+	Pagination  string `json:"pagination,omitempty"`  // offset | cursor - default: offset
+	BatchSize   int    `json:"batch_size,omitempty"`  // max items of batch requests - default: 100
+
+	// Generators declares project-specific make: commands
+	Generators []GeneratorConfig `json:"generators,omitempty"`
 }
 
 var (
@@ -97,16 +103,20 @@ func NewProject(name, template, deployment, router string) error {
 		fmt.Printf("\n"+red+"Hata:"+reset+" JSON yazılamadı: %v\n", err)
 		return err
 	}
-	abs, _ := filepath.Abs(out)
-	printSuccessBox("Kaydedildi: " + abs)
+	if !DryRun {
+		abs, _ := filepath.Abs(out)
+		printSuccessBox("Kaydedildi: " + abs)
+	}
 
 	// Write .env file with router configuration
 	if err := writeEnvFile(cfg); err != nil {
 		fmt.Printf("\n"+red+"Hata:"+reset+" .env dosyası yazılamadı: %v\n", err)
 		return err
 	}
-	envAbs, _ := filepath.Abs(".env")
-	printSuccessBox("Kaydedildi: " + envAbs)
+	if !DryRun {
+		envAbs, _ := filepath.Abs(".env")
+		printSuccessBox("Kaydedildi: " + envAbs)
+	}
 	fmt.Println()
 
 	return nil
@@ -163,7 +173,7 @@ func selectFrom(label string, items []string) (string, error) {
 
 func writeJSON(file string, data interface{}) error {
 	b, _ := json.MarshalIndent(data, "", "  ")
-	return os.WriteFile(file, b, 0644)
+	return writeFile(file, b)
 }
 
 // writeEnvFile creates a .env file with router configuration
@@ -174,7 +184,7 @@ func writeEnvFile(cfg Config) error {
 	}
 
 	envContent := fmt.Sprintf("# Generated by WentPlate\n# Router configuration\nROUTER=%s\n", router)
-	return os.WriteFile(".env", []byte(envContent), 0644)
+	return writeFile(".env", []byte(envContent))
 }
 
 func printSection(title string) {
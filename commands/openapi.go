@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"went-plate/internal/inspect"
+	"went-plate/internal/openapi"
+)
+
+// OpenAPICommands handles all openapi: commands
+func OpenAPICommands() {
+	if len(os.Args) < 2 {
+		fmt.Println("Usage: went openapi:generate [--output openapi.yaml] [--ui]")
+		return
+	}
+
+	command := os.Args[1]
+
+	switch command {
+	case "openapi:generate":
+		genCmd := flag.NewFlagSet("openapi:generate", flag.ExitOnError)
+		output := genCmd.String("output", "openapi.yaml", "Output file")
+		genCmd.StringVar(output, "o", "openapi.yaml", "Output file")
+		ui := genCmd.Bool("ui", false, "Generate app/docs serving Swagger UI and Redoc")
+		genCmd.Parse(os.Args[2:])
+
+		if err := GenerateOpenAPI(*output, *ui); err != nil {
+			fmt.Printf("%s[ERROR]%s %v\n", red, reset, err)
+			os.Exit(1)
+		}
+
+	default:
+		fmt.Printf("%s[ERROR]%s Unknown openapi command: %s\n", red, reset, command)
+		fmt.Println("Available commands: openapi:generate")
+	}
+}
+
+// GenerateOpenAPI builds an OpenAPI 3.1 document from app/models and
+// app/controllers and writes it to output
+func GenerateOpenAPI(output string, ui bool) error {
+	title := "your-app"
+	if config, err := readProjectConfig(); err == nil {
+		title = config.ProjectName
+	}
+
+	models, err := inspect.ParseModels("app/models")
+	if err != nil {
+		return err
+	}
+
+	var controllers []inspect.Controller
+	if _, err := os.Stat("app/controllers"); err == nil {
+		controllers, err = inspect.ParseControllers("app/controllers")
+		if err != nil {
+			return err
+		}
+	}
+
+	doc := openapi.Build(title, models, controllers)
+	content, err := doc.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to encode OpenAPI document: %v", err)
+	}
+
+	if err := writeFile(output, content); err != nil {
+		return fmt.Errorf("failed to write %s: %v", output, err)
+	}
+	if !DryRun {
+		fmt.Printf("%s[OK]%s OpenAPI spec written to %s (%d schemas, %d paths)\n", green, reset, output, len(doc.Components.Schemas), len(doc.Paths))
+	}
+
+	// Keep the embedded copy served by app/docs in sync
+	docsDir := filepath.Join("app", "docs")
+	if ui || fileExists(filepath.Join(docsDir, "docs.go")) {
+		if err := writeFile(filepath.Join(docsDir, "openapi.yaml"), content); err != nil {
+			return fmt.Errorf("failed to write docs spec: %v", err)
+		}
+		CreateFileFromTemplate("internal/templates/docs.tpl", "app/docs/docs.go", "Docs")
+	}
+
+	return nil
+}
+
+// MakeFromOpenAPI generates models, request DTOs, controllers and route
+// registration from an existing OpenAPI 3 document
+func MakeFromOpenAPI(specPath, router string) error {
+	doc, err := openapi.Load(specPath)
+	if err != nil {
+		return err
+	}
+
+	projectName := "your-app"
+	if config, err := readProjectConfig(); err == nil {
+		projectName = config.ProjectName
+	}
+	modulePath := projectModulePath(projectName)
+
+	plan := openapi.Plan(doc)
+
+	for _, model := range plan.Models {
+		data := struct {
+			ProjectName string
+			ModulePath  string
+			Struct      openapi.StructDef
+		}{projectName, modulePath, model}
+		if err := writeStub("openapi_model", "app/models/"+model.Name+".go", data); err != nil {
+			return err
+		}
+	}
+
+	if len(plan.Requests) > 0 {
+		data := struct {
+			ProjectName string
+			ModulePath  string
+			Requests    []openapi.StructDef
+			UsesModels  bool
+			UsesTime    bool
+		}{ProjectName: projectName, ModulePath: modulePath, Requests: plan.Requests}
+		for _, req := range plan.Requests {
+			data.UsesModels = data.UsesModels || req.UsesModels
+			data.UsesTime = data.UsesTime || len(req.Imports) > 0
+		}
+		if err := writeStub("openapi_requests", "app/requests/requests.go", data); err != nil {
+			return err
+		}
+	}
+
+	for _, controller := range plan.Controllers {
+		data := struct {
+			ProjectName    string
+			ModulePath     string
+			Controller     openapi.ControllerDef
+			UsesModels     bool
+			UsesRequests   bool
+			UsesPathParams bool
+		}{ProjectName: projectName, ModulePath: modulePath, Controller: controller}
+		for _, op := range controller.Operations {
+			data.UsesModels = data.UsesModels || strings.Contains(op.RequestType, "models.")
+			data.UsesRequests = data.UsesRequests || strings.HasPrefix(op.RequestType, "requests.")
+			data.UsesPathParams = data.UsesPathParams || len(op.PathParams) > 0
+		}
+		if err := writeStub("openapi_controller_"+router, "app/controllers/"+controller.Name+"Controller.go", data); err != nil {
+			return err
+		}
+	}
+
+	if err := ensureErrorPackage(); err != nil {
+		return err
+	}
+
+	routes := struct {
+		ProjectName string
+		ModulePath  string
+		Spec        string
+		Controllers []openapi.ControllerDef
+	}{projectName, modulePath, filepath.Base(specPath), plan.Controllers}
+	if err := writeStub("openapi_routes_"+router, "app/routes/openapi.go", routes); err != nil {
+		return err
+	}
+
+	if DryRun {
+		return nil
+	}
+	fmt.Printf("%s[OK]%s Generated %d models, %d request DTOs and %d controllers from %s using %s router\n",
+		green, reset, len(plan.Models), len(plan.Requests), len(plan.Controllers), specPath, router)
+	fmt.Println("Register the routes with routes.RegisterOpenAPIRoutes(router, db)")
+	return nil
+}
+
+// writeStub renders a template and writes it unless the file already exists
+func writeStub(templateName, outputPath string, data interface{}) error {
+	content, err := renderGenerated(templateName, outputPath, data)
+	if err != nil {
+		return err
+	}
+	if writeGeneratedFile(outputPath, templateName, content) == fileWritten && !DryRun {
+		fmt.Printf("%s[OK]%s Created %s\n", green, reset, outputPath)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,6 +11,7 @@ import (
 // PackageManager handles downloading and managing packages in pkg/ folder
 type PackageManager struct {
 	PkgDir string
+	Force  bool // reinstall packages that already exist, keeping a .orig backup
 }
 
 // NewPackageManager creates a new package manager instance
@@ -21,27 +23,59 @@ func NewPackageManager() *PackageManager {
 
 // InstallPackage downloads a package from a git repository
 func (pm *PackageManager) InstallPackage(repoURL, packageName string) error {
-	// Create pkg directory if it doesn't exist
-	if err := os.MkdirAll(pm.PkgDir, 0755); err != nil {
-		return fmt.Errorf("failed to create pkg directory: %v", err)
-	}
-
 	packagePath := filepath.Join(pm.PkgDir, packageName)
 
 	// Check if package already exists
+	exists := false
 	if _, err := os.Stat(packagePath); err == nil {
-		fmt.Printf("%s[WARN]%s Package '%s' already exists. Use --force to overwrite.\n", yellow, reset, packageName)
+		if !pm.Force {
+			fmt.Printf("%s[WARN]%s Package '%s' already exists. Use --force to overwrite.\n", yellow, reset, packageName)
+			return nil
+		}
+		exists = true
+	}
+
+	if DryRun {
+		if exists {
+			fmt.Printf("%s[DRY-RUN]%s would back up %s to %s.orig\n", cyan, reset, packagePath, packagePath)
+		}
+		fmt.Printf("%s[DRY-RUN]%s would clone %s into %s\n", cyan, reset, repoURL, packagePath)
 		return nil
 	}
 
+	// Keep the previous version next to the new one
+	backupPath := packagePath + ".orig"
+	if exists {
+		if err := os.RemoveAll(backupPath); err != nil {
+			return fmt.Errorf("failed to remove old backup: %v", err)
+		}
+		if err := os.Rename(packagePath, backupPath); err != nil {
+			return fmt.Errorf("failed to back up existing package: %v", err)
+		}
+		fmt.Printf("%s[INFO]%s Backed up existing package to %s\n", blue, reset, backupPath)
+	}
+
 	fmt.Printf("%s[INFO]%s Installing package '%s' from %s...\n", blue, reset, packageName, repoURL)
 
+	// Create pkg directory if it doesn't exist
+	if err := os.MkdirAll(pm.PkgDir, 0755); err != nil {
+		return fmt.Errorf("failed to create pkg directory: %v", err)
+	}
+
 	// Clone the repository
 	cmd := exec.Command("git", "clone", repoURL, packagePath)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
+		// Don't leave a partial clone behind, nor the user without the package
+		os.RemoveAll(packagePath)
+		if exists {
+			if restoreErr := os.Rename(backupPath, packagePath); restoreErr != nil {
+				return fmt.Errorf("failed to clone repository: %v (restoring %s failed: %v)", err, backupPath, restoreErr)
+			}
+			fmt.Printf("%s[INFO]%s Restored the previous version of '%s'\n", blue, reset, packageName)
+		}
 		return fmt.Errorf("failed to clone repository: %v", err)
 	}
 
@@ -91,6 +125,11 @@ func (pm *PackageManager) RemovePackage(packageName string) error {
 		return nil
 	}
 
+	if DryRun {
+		fmt.Printf("%s[DRY-RUN]%s would remove %s\n", cyan, reset, packagePath)
+		return nil
+	}
+
 	if err := os.RemoveAll(packagePath); err != nil {
 		return fmt.Errorf("failed to remove package: %v", err)
 	}
@@ -125,13 +164,16 @@ func PackageCommands() {
 
 	switch command {
 	case "pkg:install":
-		if len(os.Args) < 4 {
-			fmt.Println("Usage: went pkg:install <repo-url> <package-name>")
+		installCmd := flag.NewFlagSet(command, flag.ExitOnError)
+		installCmd.BoolVar(&pm.Force, "force", false, "Reinstall an existing package (a .orig backup is kept)")
+		args := parseInterspersed(installCmd, os.Args[2:])
+		if len(args) < 2 {
+			fmt.Println("Usage: went pkg:install <repo-url> <package-name> [--force]")
 			fmt.Println("Example: went pkg:install https://github.com/gin-gonic/gin gin")
 			return
 		}
-		repoURL := os.Args[2]
-		packageName := os.Args[3]
+		repoURL := args[0]
+		packageName := args[1]
 		if err := pm.InstallPackage(repoURL, packageName); err != nil {
 			fmt.Printf("%s[ERROR]%s %v\n", red, reset, err)
 		}
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstallPackageForceRestoresPackageWhenCloneFails(t *testing.T) {
+	t.Setenv("GIT_TERMINAL_PROMPT", "0")
+	pm := &PackageManager{PkgDir: t.TempDir(), Force: true}
+	packagePath := filepath.Join(pm.PkgDir, "tools")
+	if err := os.MkdirAll(packagePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(packagePath, "tools.go"), []byte("package tools\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	unreachable := "file://" + filepath.Join(t.TempDir(), "missing.git")
+	if err := pm.InstallPackage(unreachable, "tools"); err == nil {
+		t.Fatal("expected the clone of an unreachable repository to fail")
+	}
+
+	data, err := os.ReadFile(filepath.Join(packagePath, "tools.go"))
+	if err != nil {
+		t.Fatalf("the previous package was not restored: %v", err)
+	}
+	if string(data) != "package tools\n" {
+		t.Errorf("restored package has unexpected content %q", data)
+	}
+	if _, err := os.Stat(packagePath + ".orig"); !os.IsNotExist(err) {
+		t.Errorf("the backup should have been moved back, got %v", err)
+	}
+}
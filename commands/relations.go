@@ -0,0 +1,242 @@
+package commands
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"went-plate/internal/inflect"
+	"went-plate/internal/inspect"
+)
+
+// Relation flags hold the associations given to make:model
+var belongsToFlag, hasManyFlag, manyToManyFlag string
+
+// TemplateRelation describes an association of the generated model
+type TemplateRelation struct {
+	Kind       string // "belongs-to", "has-one", "has-many" or "many-to-many"
+	Name       string // association field, e.g. "Author"
+	Model      string // associated model, e.g. "User"
+	Include    string // name accepted by ?include=, e.g. "author"
+	ForeignKey string // e.g. "AuthorID": a field of this model for belongs-to, of the associated one for has-many
+	JoinTable  string // join table of many-to-many relations, e.g. "post_tags"
+}
+
+// Type returns the Go type of the association field
+func (r TemplateRelation) Type() string {
+	if r.Kind == "has-many" || r.Kind == "many-to-many" {
+		return "[]" + r.Model
+	}
+	return "*" + r.Model
+}
+
+// Tag returns the struct tag of the association field, including the backquotes
+func (r TemplateRelation) Tag() string {
+	gorm := "foreignKey:" + r.ForeignKey
+	if r.Kind == "many-to-many" {
+		gorm = "many2many:" + r.JoinTable
+	}
+	return fmt.Sprintf("`json:\"%s,omitempty\" gorm:\"%s\" validate:\"-\"`", r.Include, gorm)
+}
+
+// Route returns the path segment of the nested route listing a has-many
+// relation ("BlogPosts" -> "blog-posts")
+func (r TemplateRelation) Route() string {
+	return inflect.Kebab(r.Name)
+}
+
+// HasMany returns the has-many relations, which get nested routes
+func (d TemplateData) HasMany() []TemplateRelation {
+	var relations []TemplateRelation
+	for _, r := range d.Relations {
+		if r.Kind == "has-many" {
+			relations = append(relations, r)
+		}
+	}
+	return relations
+}
+
+// parseRelations parses a relation flag: comma or space separated
+// [name:]Model[:key] entries, e.g. "author:User" or "Comment". The key is the
+// foreign key field for belongs-to and has-many, the join table for
+// many-to-many.
+func parseRelations(kind, spec, modelName string) ([]TemplateRelation, error) {
+	var relations []TemplateRelation
+	for _, entry := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == ' ' }) {
+		parts := strings.Split(entry, ":")
+		if len(parts) == 1 {
+			parts = []string{"", parts[0]}
+		}
+		if len(parts) > 3 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid --%s entry '%s': expected [name:]Model[:key]", kind, entry)
+		}
+		if strings.ContainsAny(parts[1], `/\`) {
+			return nil, fmt.Errorf("invalid --%s entry '%s': related models must live in the package of %s", kind, entry, modelName)
+		}
+
+		r := TemplateRelation{Kind: kind, Model: inflect.Pascal(parts[1])}
+		r.Name = inflect.Pascal(parts[0])
+		switch {
+		case r.Name != "":
+		case kind == "belongs-to":
+			r.Name = r.Model
+		default:
+			r.Name = inflect.Plural(r.Model)
+		}
+		if !token.IsIdentifier(r.Name) || !token.IsIdentifier(r.Model) {
+			return nil, fmt.Errorf("invalid --%s entry '%s'", kind, entry)
+		}
+		r.Include = inflect.Snake(r.Name)
+
+		key := ""
+		if len(parts) == 3 {
+			key = parts[2]
+		}
+		switch kind {
+		case "belongs-to":
+			r.ForeignKey = r.Name + "ID"
+		case "has-many":
+			r.ForeignKey = modelName + "ID"
+		case "many-to-many":
+			r.JoinTable = joinTable(modelName, r.Model)
+		}
+		if key != "" && kind == "many-to-many" {
+			r.JoinTable = key
+		} else if key != "" {
+			r.ForeignKey = inflect.Pascal(key)
+		}
+		relations = append(relations, r)
+	}
+	return relations, nil
+}
+
+// joinTable returns the conventional join table of two models, the same from
+// either side ("Tag", "Post" -> "post_tags")
+func joinTable(a, b string) string {
+	a, b = inflect.Snake(a), inflect.Snake(b)
+	if b < a {
+		a, b = b, a
+	}
+	return a + "_" + inflect.Plural(b)
+}
+
+// templateRelations returns the relations of generator n: those given with
+// --belongs-to, --has-many and --many-to-many, otherwise the associations of
+// the existing model
+func templateRelations(n generatorName) ([]TemplateRelation, error) {
+	if belongsToFlag != "" || hasManyFlag != "" || manyToManyFlag != "" {
+		var relations []TemplateRelation
+		seen := map[string]bool{}
+		for _, flag := range [][2]string{{"belongs-to", belongsToFlag}, {"has-many", hasManyFlag}, {"many-to-many", manyToManyFlag}} {
+			parsed, err := parseRelations(flag[0], flag[1], n.Name)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range parsed {
+				if seen[r.Name] {
+					return nil, fmt.Errorf("relation '%s' is listed twice", r.Name)
+				}
+				seen[r.Name] = true
+				related := generatorName{Segments: n.Segments, Name: r.Model}
+				m, _ := existingModel(related)
+				switch {
+				case r.Model == n.Name:
+				case m == nil && r.Kind == "has-many":
+					fmt.Printf("%s[WARN]%s Model '%s' doesn't exist yet; create it with went make:model %s --belongs-to %s\n", yellow, reset, related, related, n.Name)
+				case m == nil:
+					fmt.Printf("%s[WARN]%s Model '%s' doesn't exist yet; create it with went make:model %s\n", yellow, reset, related, related)
+				case r.Kind == "has-many" && !hasField(m, r.ForeignKey):
+					return nil, fmt.Errorf("model '%s' has no %s field for the has-many relation '%s'; add it with went make:model %s --belongs-to %s, or name its key with --has-many %s:%s:<Key>", related, r.ForeignKey, r.Name, related, n.Name, r.Name, r.Model)
+				}
+			}
+			relations = append(relations, parsed...)
+		}
+		return relations, nil
+	}
+
+	m, models := existingModel(n)
+	if m == nil {
+		return nil, nil
+	}
+	var relations []TemplateRelation
+	for _, a := range m.Associations(models) {
+		include := a.JSONName
+		if include == "" {
+			include = inflect.Snake(a.Field)
+		}
+		relations = append(relations, TemplateRelation{
+			Kind:       a.Kind,
+			Name:       a.Field,
+			Model:      a.Model,
+			Include:    include,
+			ForeignKey: a.ForeignKey,
+			JoinTable:  a.JoinTable,
+		})
+	}
+	return relations, nil
+}
+
+// foreignKeyFields returns the foreign key fields the belongs-to and
+// self-referencing has-many relations need and fields doesn't declare yet. Keys match the primary key of the
+// related model, or primaryKey when it doesn't exist yet.
+func foreignKeyFields(n generatorName, relations []TemplateRelation, fields []TemplateField, primaryKey, database string) ([]TemplateField, error) {
+	declared := map[string]bool{}
+	for _, f := range fields {
+		declared[f.Name] = true
+	}
+
+	var keys []TemplateField
+	for _, r := range relations {
+		if declared[r.Name] {
+			return nil, fmt.Errorf("relation '%s' clashes with the field of the same name", r.Name)
+		}
+		// Self-referencing has-many relations keep their key in this model too
+		if r.Kind != "belongs-to" && (r.Kind != "has-many" || r.Model != n.Name) || declared[r.ForeignKey] {
+			continue
+		}
+		keyType := primaryKey
+		if related, _ := existingModel(generatorName{Segments: n.Segments, Name: r.Model}); related != nil && r.Model != n.Name {
+			keyType = modelPrimaryKey(related)
+		}
+		gorm := "index"
+		if column := idColumnType(keyType, database); column != "" {
+			gorm += ";" + column
+		}
+		keys = append(keys, newTemplateField(r.ForeignKey, primaryKeyTypes[keyType], gorm, ""))
+		declared[r.ForeignKey] = true
+	}
+	return keys, nil
+}
+
+// idColumnType returns the gorm column setting of a primary key type, empty
+// for integer keys
+func idColumnType(primaryKey, database string) string {
+	switch {
+	case primaryKey == "uuid" && database == "postgres":
+		return "type:uuid"
+	case primaryKey == "uuid":
+		return "size:36"
+	case primaryKey == "ulid":
+		return "size:26"
+	}
+	return ""
+}
+
+// associationFields returns the names of m's association fields
+func associationFields(m *inspect.Model, models []inspect.Model) map[string]bool {
+	names := map[string]bool{}
+	for _, a := range m.Associations(models) {
+		names[a.Field] = true
+	}
+	return names
+}
+
+// hasField reports whether m declares a field called name
+func hasField(m *inspect.Model, name string) bool {
+	for _, f := range m.Fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"went-plate/internal/manifest"
+)
+
+// StatusCommand lists the generated files of the project and whether they
+// are still pristine or were customized since generation
+func StatusCommand() {
+	m, err := manifest.Load(manifest.Path)
+	if err != nil {
+		fmt.Printf("%s[ERROR]%s %v\n", red, reset, err)
+		os.Exit(1)
+	}
+	if len(m.Files) == 0 {
+		fmt.Printf("%s[INFO]%s No generated files are recorded in %s\n", blue, reset, manifest.Path)
+		return
+	}
+
+	width := 0
+	for path := range m.Files {
+		width = max(width, len(path))
+	}
+
+	counts := map[manifest.State]int{}
+	outdated := 0
+	fmt.Printf("%sGenerated files%s (%s):\n", bold, reset, manifest.Path)
+	for _, path := range m.Paths() {
+		entry := m.Files[path]
+		state := entry.Check(path)
+		counts[state]++
+
+		color := green
+		switch state {
+		case manifest.Customized:
+			color = yellow
+		case manifest.Missing:
+			color = red
+		}
+
+		note := dim + strings.TrimSpace(entry.Generator+" "+strings.Join(entry.Inputs, " ")) + reset
+		if current := templateVersion(entry.Template); current != "" && current != entry.TemplateVersion {
+			outdated++
+			note += " " + cyan + "(template updated)" + reset
+		}
+		fmt.Printf("  %s%-12s%s %-*s  %s\n", color, "["+string(state)+"]", reset, width, path, note)
+	}
+
+	fmt.Printf("\n%d pristine, %d customized, %d missing\n", counts[manifest.Pristine], counts[manifest.Customized], counts[manifest.Missing])
+	if outdated > 0 {
+		fmt.Printf("%s[INFO]%s %d file(s) were generated from an older template; rerun the generator with --merge to update them\n", blue, reset, outdated)
+	}
+}
+
+// generatedState reports whether path was generated by WentPlate and, if so,
+// whether it is still pristine. Files generated before the manifest existed
+// are compared with their copy in .went/base.
+func generatedState(path string) (manifest.State, bool) {
+	m, err := manifest.Load(manifest.Path)
+	if err == nil {
+		if entry, ok := m.Files[filepath.ToSlash(filepath.Clean(path))]; ok {
+			return entry.Check(path), true
+		}
+	}
+
+	base, err := os.ReadFile(basePath(path))
+	if err != nil {
+		return "", false
+	}
+	current, err := os.ReadFile(path)
+	switch {
+	case err != nil:
+		return manifest.Missing, true
+	case !bytes.Equal(base, current):
+		return manifest.Customized, true
+	default:
+		return manifest.Pristine, true
+	}
+}
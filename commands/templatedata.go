@@ -0,0 +1,342 @@
+package commands
+
+import (
+	"crypto/rand"
+	"fmt"
+	"go/token"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"went-plate/internal/inflect"
+	"went-plate/internal/inspect"
+)
+
+// fieldsFlag holds the field list given to make:model with --fields
+var fieldsFlag string
+
+// pkFlag holds the primary key type given to make:model with --pk
+var pkFlag string
+
+// paginationFlag holds the list pagination mode given with --pagination
+var paginationFlag string
+
+// versionedFlag adds a version column for optimistic locking, set with --versioned
+var versionedFlag bool
+
+// primaryKeyTypes maps the accepted primary key types to the Go type of ID
+var primaryKeyTypes = map[string]string{"int": "uint", "uuid": "string", "ulid": "string"}
+
+// TemplateData is passed to every generator template
+type TemplateData struct {
+	ModelName      string // e.g. "UserProfile"
+	PluralName     string // e.g. "UserProfiles"
+	VarName        string // e.g. "userProfile"
+	PluralVarName  string // e.g. "userProfiles"
+	TableName      string // e.g. "user_profiles", or the --table override
+	RouteName      string // e.g. "admin/user-profiles"
+	Package        string // package of a namespaced generator, empty for the root package
+	Namespace      string // namespace directory, e.g. "admin"
+	ModelsImport   string
+	PoliciesImport string
+	ProjectName    string
+	AppName        string // For backward compatibility
+	ModulePath     string // module path of the project's go.mod, e.g. "github.com/acme/orders"
+	HasPolicy      bool
+	PrimaryKey     string             // "int", "uuid" or "ulid"
+	IDType         string             // Go type of ID: "uint", or "string" for UUIDs and ULIDs
+	Pagination     string             // "offset" or "cursor"
+	Versioned      bool               // the model has a Version column checked on update
+	Config         Config             // the project's wentconfig.json
+	Fields         []TemplateField    // model fields, excluding ID and timestamps
+	Relations      []TemplateRelation // associations with other models
+	Args           map[string]string  // named arguments of custom generators
+}
+
+// TemplateField describes a model field for templates
+type TemplateField struct {
+	Name     string // Go field name, e.g. "PublishedAt"
+	Type     string // Go type, e.g. "time.Time"
+	Column   string // database column, e.g. "published_at"
+	JSONName string // e.g. "published_at"
+	Gorm     string // gorm tag, e.g. "column:published_at"
+	Validate string // validate tag, e.g. "required,max=120"
+	Required bool   // the validate tag contains a required rule
+}
+
+// Tag returns the struct tag of the field, including the backquotes
+func (f TemplateField) Tag() string {
+	tag := fmt.Sprintf(`json:"%s" gorm:"%s"`, f.JSONName, f.Gorm)
+	if f.Validate != "" {
+		tag += fmt.Sprintf(` validate:"%s"`, f.Validate)
+	}
+	return "`" + tag + "`"
+}
+
+// StringFields returns the fields of type string, e.g. for text search
+func (d TemplateData) StringFields() []TemplateField {
+	var fields []TemplateField
+	for _, f := range d.Fields {
+		if f.Type == "string" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// defaultFields are generated when make:model is run without --fields.
+// Generated UUIDs and ULIDs are set before validation, so ID can't tell
+// whether a record is new.
+func defaultFields(primaryKey string) []TemplateField {
+	name := "required_if=ID 0"
+	if primaryKey != "int" {
+		name = "required"
+	}
+	return []TemplateField{
+		newTemplateField("Name", "string", "", name),
+		newTemplateField("Description", "string", "", "omitempty,max=255"),
+	}
+}
+
+// fieldTypes maps the types accepted by --fields to Go types and extra gorm settings
+var fieldTypes = map[string][2]string{
+	"string": {"string", ""}, "text": {"string", "type:text"},
+	"int": {"int", ""}, "integer": {"int", ""}, "int64": {"int64", ""}, "uint": {"uint", ""},
+	"float": {"float64", ""}, "float64": {"float64", ""}, "decimal": {"float64", "type:decimal(10,2)"},
+	"bool": {"bool", ""}, "boolean": {"bool", ""},
+	"time": {"time.Time", ""}, "datetime": {"time.Time", ""}, "timestamp": {"time.Time", ""}, "date": {"time.Time", "type:date"},
+}
+
+// newTemplateField builds a field with the conventional column and JSON name
+func newTemplateField(name, goType, gorm, validate string) TemplateField {
+	column := inflect.Snake(name)
+	if gorm == "" {
+		gorm = "column:" + column
+	} else {
+		gorm = "column:" + column + ";" + gorm
+	}
+	return TemplateField{
+		Name:     inflect.Pascal(name),
+		Type:     goType,
+		Column:   column,
+		JSONName: column,
+		Gorm:     gorm,
+		Validate: validate,
+		Required: strings.Contains(validate, "required"),
+	}
+}
+
+// parseFields parses a --fields list: space separated name:type[:validate]
+// entries, e.g. "title:string:required,max=120 price:decimal published_at:time".
+// Types not listed in fieldTypes are used as Go types verbatim.
+func parseFields(spec string) ([]TemplateField, error) {
+	var fields []TemplateField
+	seen := map[string]bool{}
+	for _, entry := range strings.Fields(spec) {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid field '%s': expected name:type[:validate]", entry)
+		}
+		goType, gorm := parts[1], ""
+		if t, ok := fieldTypes[strings.ToLower(parts[1])]; ok {
+			goType, gorm = t[0], t[1]
+		}
+		validate := ""
+		if len(parts) == 3 {
+			validate = parts[2]
+		}
+
+		field := newTemplateField(parts[0], goType, gorm, validate)
+		switch field.Name {
+		case "ID", "CreatedAt", "UpdatedAt", "DeletedAt":
+			return nil, fmt.Errorf("field '%s' is always generated", field.Name)
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("field '%s' is listed twice", field.Name)
+		}
+		seen[field.Name] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// templateFields returns the fields for generator n: the --fields list when
+// given, otherwise those of the existing model, otherwise defaultFields
+func templateFields(n generatorName, primaryKey string) ([]TemplateField, error) {
+	if strings.TrimSpace(fieldsFlag) != "" {
+		return parseFields(fieldsFlag)
+	}
+
+	m, models := existingModel(n)
+	if m == nil {
+		return defaultFields(primaryKey), nil
+	}
+	associations := associationFields(m, models)
+	var fields []TemplateField
+	for _, f := range m.Fields {
+		switch {
+		case f.Embedded, f.JSONName == "", associations[f.Name]:
+			continue
+		case f.Name == "ID", f.Name == "CreatedAt", f.Name == "UpdatedAt", f.Name == "DeletedAt":
+			continue
+		case f.Name == "Version" && m.Versioned:
+			continue
+		}
+		field := TemplateField{
+			Name:     f.Name,
+			Type:     f.Type,
+			Column:   inflect.Snake(f.Name),
+			JSONName: f.JSONName,
+			Gorm:     f.Gorm,
+			Validate: f.Validate,
+			Required: strings.Contains(f.Validate, "required"),
+		}
+		for _, setting := range strings.Split(f.Gorm, ";") {
+			if column, ok := strings.CutPrefix(setting, "column:"); ok {
+				field.Column = column
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// templatePrimaryKey returns the primary key type for generator n: --pk when
+// given, otherwise that of the existing model, otherwise the project default
+func templatePrimaryKey(n generatorName, config *Config) (string, error) {
+	primaryKey := strings.ToLower(pkFlag)
+	if primaryKey == "" {
+		if m, _ := existingModel(n); m != nil {
+			return modelPrimaryKey(m), nil
+		}
+		primaryKey = strings.ToLower(config.PrimaryKey)
+	}
+	if primaryKey == "" {
+		return "int", nil
+	}
+	if _, ok := primaryKeyTypes[primaryKey]; !ok {
+		return "", fmt.Errorf("invalid primary key type '%s': expected int, uuid or ulid", primaryKey)
+	}
+	return primaryKey, nil
+}
+
+// templatePagination returns the pagination mode for generator n: --pagination
+// when given, otherwise that of the existing model, otherwise the project default
+func templatePagination(n generatorName, config *Config) (string, error) {
+	pagination := strings.ToLower(paginationFlag)
+	if pagination == "" {
+		if m, _ := existingModel(n); m != nil {
+			return modelPagination(m), nil
+		}
+		pagination = strings.ToLower(config.Pagination)
+	}
+	switch pagination {
+	case "":
+		return "offset", nil
+	case "offset", "cursor":
+		return pagination, nil
+	}
+	return "", fmt.Errorf("invalid pagination '%s': expected offset or cursor", pagination)
+}
+
+// templateVersioned reports whether generator n's model is versioned: with
+// --versioned, or when the existing model has a version column
+func templateVersioned(n generatorName) bool {
+	if versionedFlag {
+		return true
+	}
+	m, _ := existingModel(n)
+	return m != nil && m.Versioned
+}
+
+// modelPagination returns the pagination mode of a parsed model
+func modelPagination(m *inspect.Model) string {
+	if m.Keyset {
+		return "cursor"
+	}
+	return "offset"
+}
+
+// modelPrimaryKey returns the primary key type of a parsed model
+func modelPrimaryKey(m *inspect.Model) string {
+	for _, f := range m.Fields {
+		if f.Name != "ID" {
+			continue
+		}
+		switch {
+		case f.Type != "string":
+			return "int"
+		case strings.Contains(f.Gorm, "size:26"):
+			return "ulid"
+		default:
+			return "uuid"
+		}
+	}
+	return "int"
+}
+
+// existingModel returns the parsed model generator n refers to, if it
+// exists, along with all models of the project
+func existingModel(n generatorName) (*inspect.Model, []inspect.Model) {
+	models, err := inspect.ParseModels("app/models")
+	if err != nil {
+		return nil, nil
+	}
+	for i, m := range models {
+		if m.Name == n.Name && m.Namespace == n.Dir() {
+			return &models[i], models
+		}
+	}
+	return nil, models
+}
+
+// templateFuncs are available in every template, including overrides
+var templateFuncs = template.FuncMap{
+	"plural":     inflect.Plural,
+	"singular":   inflect.Singular,
+	"pascal":     inflect.Pascal,
+	"camel":      inflect.Camel,
+	"snake":      inflect.Snake,
+	"kebab":      inflect.Kebab,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"lowerFirst": lowerFirst,
+	"receiver":   receiverName,
+	"now":        time.Now,
+	"uuid":       newUUID,
+}
+
+// lowerFirst lower cases the first letter of s ("UserProfile" -> "userProfile")
+func lowerFirst(s string) string {
+	first, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToLower(first)) + s[size:]
+}
+
+// receiverName returns a short method receiver name made of the initials of
+// the type name ("UserProfile" -> "up")
+func receiverName(typeName string) string {
+	var initials strings.Builder
+	for _, w := range inflect.Words(typeName) {
+		first, _ := utf8.DecodeRuneInString(w)
+		initials.WriteRune(unicode.ToLower(first))
+	}
+	name := initials.String()
+	if token.IsKeyword(name) && len(name) > 1 {
+		name = name[:1]
+	}
+	return name
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:]) // never returns an error
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
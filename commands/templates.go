@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"went-plate/internal/embedded"
+)
+
+// TemplateCommands handles all templates: commands for customizing templates
+func TemplateCommands() {
+	command := os.Args[1]
+
+	switch command {
+	case "templates:list":
+		ListAvailableTemplates()
+
+	case "templates:publish":
+		publishCmd := flag.NewFlagSet(command, flag.ExitOnError)
+		all := publishCmd.Bool("all", false, "Publish every embedded template")
+		global := publishCmd.Bool("global", false, "Publish to the user-level directory instead of .went/templates")
+		force := publishCmd.Bool("force", false, "Overwrite an existing override")
+		names := parseInterspersed(publishCmd, os.Args[2:])
+
+		if *all {
+			var err error
+			if names, err = embeddedTemplateNames(); err != nil {
+				fmt.Printf("%s[ERROR]%s Failed to list templates: %v\n", red, reset, err)
+				os.Exit(1)
+			}
+		}
+		if len(names) == 0 {
+			fmt.Println("Usage: went templates:publish <name...> [--all] [--global] [--force]")
+			fmt.Println("Example: went templates:publish controller_gin model")
+			return
+		}
+
+		dir := embedded.ProjectTemplateDir
+		if *global {
+			if dir = embedded.UserTemplateDir(); dir == "" {
+				fmt.Printf("%s[ERROR]%s Could not determine the user configuration directory\n", red, reset)
+				os.Exit(1)
+			}
+		}
+		for _, name := range names {
+			if err := PublishTemplate(name, dir, *force); err != nil {
+				fmt.Printf("%s[ERROR]%s %v\n", red, reset, err)
+				os.Exit(1)
+			}
+		}
+
+	default:
+		fmt.Printf("%s[ERROR]%s Unknown templates command: %s\n", red, reset, command)
+		fmt.Println("Available commands: templates:list, templates:publish")
+	}
+}
+
+// PublishTemplate copies the embedded version of a template into dir, where
+// it overrides the embedded one
+func PublishTemplate(name, dir string, force bool) error {
+	if current := embedded.CurrentName(name); current != name {
+		fmt.Printf("%s[INFO]%s Template '%s' is now called '%s'\n", blue, reset, name, current)
+		name = current
+	}
+	content, err := embedded.GetEmbeddedTemplate(name)
+	if err != nil {
+		return fmt.Errorf("unknown template '%s'; run 'went templates:list' to see the available ones", name)
+	}
+
+	target := filepath.Join(dir, name+".tpl")
+	if fileExists(target) && !force {
+		fmt.Printf("Skipped (already exists): %s (use --force to overwrite)\n", target)
+		return nil
+	}
+	if err := writeFile(target, content); err != nil {
+		return fmt.Errorf("failed to write %s: %v", target, err)
+	}
+	if !DryRun {
+		fmt.Printf("%s[OK]%s Published %s to %s\n", green, reset, name, target)
+	}
+	return nil
+}
+
+// embeddedTemplateNames lists the templates compiled into the binary
+func embeddedTemplateNames() ([]string, error) {
+	entries, err := embedded.TemplateFS.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if name := entry.Name(); filepath.Ext(name) == ".tpl" {
+			names = append(names, name[:len(name)-len(".tpl")])
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,536 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/mod/modfile"
+
+	"went-plate/internal/embedded"
+)
+
+// verifySample is a name every built-in generator is run for
+type verifySample struct {
+	Name   string
+	Fields string // --fields for make:model
+	PK     string // --pk for make:model
+	Policy bool   // generate a policy before the controller
+
+	// --pagination and --versioned for make:model, read back by make:controller
+	Pagination string
+	Versioned  bool
+
+	// --belongs-to, --has-many and --many-to-many for make:model
+	BelongsTo, HasMany, ManyToMany string
+}
+
+// verifySamples cover inflection (including plurals equal to the singular or
+// to a local of the templates), namespaces, field types, primary keys,
+// relations, pagination modes, version columns and the optional policy
+var verifySamples = []verifySample{
+	{Name: "Post", Policy: true, HasMany: "Comment", ManyToMany: "Tag", Versioned: true},
+	{Name: "Category"},
+	{Name: "Person"},
+	{Name: "Comment", BelongsTo: "Post,author:Person"},
+	{Name: "Tag", ManyToMany: "Post"},
+	{Name: "APIKey"},
+	{Name: "Product", Fields: "title:string:required,max=120 body:text price:decimal:gt=0 stock:int published_at:time active:bool", Pagination: "cursor"},
+	{Name: "Counter", Fields: "hits:int"},
+	{Name: "Ticket", PK: "uuid", Policy: true, HasMany: "Event", Pagination: "cursor", Versioned: true},
+	{Name: "Event", PK: "ulid", BelongsTo: "Ticket"},
+	{Name: "Admin/User", Policy: true, HasMany: "Role"},
+	{Name: "Admin/Role", BelongsTo: "User", Pagination: "cursor"},
+	{Name: "Admin/Billing/Invoice"},
+	{Name: "Campus"},
+	{Name: "Sheep", Policy: true},
+	{Name: "News", Pagination: "cursor"},
+	{Name: "Datum"},
+}
+
+// verifyMiddleware are the sample names make:middleware is run for
+var verifyMiddleware = []string{"Auth", "Admin/Audit"}
+
+// maxVerifyProblems limits the problems listed per combination
+const maxVerifyProblems = 3
+
+// verifySpec exercises the make:from-openapi templates
+const verifySpec = `openapi: 3.0.3
+info: {title: Verify, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+      parameters:
+        - {name: limit, in: query, schema: {type: integer}}
+      responses: {"200": {description: ok}}
+    post:
+      operationId: createPet
+      tags: [pets]
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/NewPet'}
+      responses: {"201": {description: created}}
+  /pets/{petId}:
+    get:
+      tags: [pets]
+      responses: {"200": {description: ok}}
+    delete:
+      tags: [pets]
+      responses: {"204": {description: deleted}}
+  /stores/{store_id}/orders:
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: array
+              items: {$ref: '#/components/schemas/Pet'}
+      responses: {"200": {description: ok}}
+components:
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string, minLength: 1, maxLength: 50}
+        tag: {type: string, enum: [dog, cat]}
+        owner_email: {type: string, format: email}
+        born_at: {type: string, format: date-time, nullable: true}
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/NewPet'
+        - type: object
+          required: [id]
+          properties:
+            id: {type: integer, format: int64}
+            friends: {type: array, items: {$ref: '#/components/schemas/Pet'}}
+`
+
+// VerifyCommand handles 'went verify'
+func VerifyCommand() {
+	verifyCmd := flag.NewFlagSet("verify", flag.ExitOnError)
+	keep := verifyCmd.Bool("keep", false, "Keep the generated module for inspection")
+	online := verifyCmd.Bool("online", false, "Download missing modules instead of using only the local module cache")
+	verifyCmd.Parse(os.Args[2:])
+
+	if err := Verify(*keep, *online); err != nil {
+		fmt.Printf("%s[ERROR]%s %v\n", red, reset, err)
+		os.Exit(1)
+	}
+}
+
+// Verify renders every template combination (router x project template, plus
+// the OpenAPI stubs per router) into a throwaway module and type-checks it
+// with go vet. Template overrides and custom generators of the current
+// project are included.
+func Verify(keep, online bool) error {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	var generators []GeneratorConfig
+	for _, g := range customGenerators() {
+		if strings.ContainsAny(g.Template, `/\`) {
+			g.Template = filepath.Join(projectDir, g.Template)
+		}
+		g.Hooks = nil
+		generators = append(generators, g)
+	}
+
+	root, err := os.MkdirTemp("", "went-verify-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	if keep {
+		fmt.Printf("%s[INFO]%s Keeping the generated module in %s\n", blue, reset, root)
+	} else {
+		defer os.RemoveAll(root)
+	}
+
+	// Generated imports resolve against this module until writeVerifyModule
+	// adds the requirements
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module verify\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write go.mod: %v", err)
+	}
+
+	// Everything below writes into root, even when --dry-run was given
+	dryRun := DryRun
+	DryRun = false
+	defer func() { DryRun = dryRun }()
+
+	problems := map[string][]string{}
+	var combos []string
+	for _, router := range []string{"gin", "chi"} {
+		for _, option := range TemplateOptions {
+			combo := router + "-" + sanitizeName(strings.ReplaceAll(option, "+", "-"))
+			combos = append(combos, combo)
+			config := Config{ProjectName: "verify/" + combo, Template: option, Deployment: "No-Deployment", Router: router, Database: "postgres", Generators: generators}
+			problems[combo] = generateVerifyProject(filepath.Join(root, combo), projectDir, config, func() {
+				verifyGenerators(router, generators)
+			})
+		}
+
+		combo := router + "-openapi"
+		combos = append(combos, combo)
+		config := Config{ProjectName: "verify/" + combo, Template: "API", Deployment: "No-Deployment", Router: router}
+		problems[combo] = generateVerifyProject(filepath.Join(root, combo), projectDir, config, func() {
+			if err := os.WriteFile("openapi.yaml", []byte(verifySpec), 0644); err != nil {
+				fmt.Printf("%s[ERROR]%s %v\n", red, reset, err)
+				return
+			}
+			if err := MakeFromOpenAPI("openapi.yaml", router); err != nil {
+				fmt.Printf("%s[ERROR]%s %v\n", red, reset, err)
+			}
+		})
+	}
+
+	fmt.Printf("%s[INFO]%s Type-checking %d combinations with go vet...\n", blue, reset, len(combos))
+	if err := writeVerifyModule(root, projectDir); err != nil {
+		return err
+	}
+	env := verifyEnv(online)
+	if online {
+		if out, err := runGo(root, env, "mod", "tidy"); err != nil {
+			return fmt.Errorf("failed to resolve the dependencies of the generated code:\n%s", out)
+		}
+	}
+	out, _ := runGo(root, env, "vet", "./...")
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(line), "# verify/"), "vet: ")
+		if line == "" {
+			continue
+		}
+		combo, _, _ := strings.Cut(strings.TrimPrefix(line, "verify/"), "/")
+		if _, ok := problems[combo]; !ok {
+			combo = ""
+		}
+		if !strings.HasPrefix(line, combo) || !strings.Contains(line, ":") {
+			continue // package headers
+		}
+		problems[combo] = append(problems[combo], line)
+	}
+
+	failed := 0
+	for _, combo := range combos {
+		if len(problems[combo]) == 0 {
+			fmt.Printf("  %s[OK]%s    %s\n", green, reset, combo)
+			continue
+		}
+		failed++
+		fmt.Printf("  %s[FAIL]%s  %s\n", red, reset, combo)
+		for i, p := range problems[combo] {
+			if i == maxVerifyProblems {
+				fmt.Printf("          ... and %d more (inspect them with --keep)\n", len(problems[combo])-i)
+				break
+			}
+			if i > 0 {
+				// Only the first problem is shown with its excerpt
+				p, _, _ = strings.Cut(p, "\n")
+			}
+			fmt.Printf("          %s\n", strings.ReplaceAll(p, "\n", "\n        "))
+		}
+	}
+	if general := problems[""]; len(general) > 0 {
+		for _, p := range general {
+			fmt.Printf("  %s\n", p)
+		}
+		failed++
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d combinations do not compile", failed, len(combos))
+	}
+	fmt.Printf("%s[OK]%s All %d combinations compile and pass go vet\n", green, reset, len(combos))
+	return nil
+}
+
+// generateVerifyProject creates a project for config in dir and runs generate
+// inside it, returning the errors the generators reported
+func generateVerifyProject(dir, projectDir string, config Config, generate func()) []string {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return []string{err.Error()}
+	}
+	if err := writeJSON(filepath.Join(dir, "wentconfig.json"), config); err != nil {
+		return []string{err.Error()}
+	}
+	// Project overrides are looked up relative to the working directory
+	if err := copyDir(filepath.Join(projectDir, embedded.ProjectTemplateDir), filepath.Join(dir, embedded.ProjectTemplateDir)); err != nil {
+		return []string{err.Error()}
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		return []string{err.Error()}
+	}
+	defer os.Chdir(projectDir)
+
+	return reportedErrors(captureOutput(generate))
+}
+
+// verifyGenerators runs the built-in and custom generators for the samples
+func verifyGenerators(router string, generators []GeneratorConfig) {
+	for _, s := range verifySamples {
+		name := parseName(s.Name)
+		fieldsFlag, pkFlag, paginationFlag, versionedFlag = s.Fields, s.PK, s.Pagination, s.Versioned
+		belongsToFlag, hasManyFlag, manyToManyFlag = s.BelongsTo, s.HasMany, s.ManyToMany
+		CreateFileFromTemplate("internal/templates/model.tpl", name.Path("app/models", ".go"), name.String())
+		ensureModelValidator(name)
+		fieldsFlag, pkFlag, paginationFlag, versionedFlag = "", "", "", false
+		belongsToFlag, hasManyFlag, manyToManyFlag = "", "", ""
+
+		CreateFileFromTemplate("internal/templates/service.tpl", name.Path("app/services", "Service.go"), name.String())
+		if s.Policy {
+			CreateFileFromTemplate("internal/templates/policy.tpl", name.Path("app/policies", "Policy.go"), name.String())
+		}
+		CreateFileFromTemplate("internal/templates/controller_"+router+".tpl", name.Path("app/controllers", "Controller.go"), name.String())
+		ensureQueryPackage(name)
+		ensureErrorPackage()
+		ensurePatchPackage()
+		ensureBatchPackage(name)
+		ensureTransferPackage(name)
+	}
+	for _, m := range verifyMiddleware {
+		name := parseName(m)
+		CreateFileFromTemplate("internal/templates/middleware_"+router+".tpl", name.Path("app/middleware", ".go"), name.String())
+	}
+
+	for _, g := range generators {
+		args := []string{"Post"}
+		for range g.Args {
+			args = append(args, "sample")
+		}
+		if err := RunGenerator(g, args); err != nil {
+			fmt.Printf("%s[ERROR]%s make:%s: %v\n", red, reset, g.Name, err)
+		}
+	}
+
+	// Exercises openapi.Build on the generated code and the docs template
+	if err := GenerateOpenAPI("openapi.yaml", true); err != nil {
+		fmt.Printf("%s[ERROR]%s openapi:generate: %v\n", red, reset, err)
+	}
+}
+
+// reportedErrors extracts the [ERROR] messages, including their indented
+// continuation lines, from generator output
+func reportedErrors(output string) []string {
+	var errs []string
+	inError := false
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.Contains(line, "[ERROR]"):
+			message := strings.TrimPrefix(line[strings.Index(line, "[ERROR]")+len("[ERROR]"):], reset)
+			errs = append(errs, strings.TrimSpace(message))
+			inError = true
+		case inError && strings.HasPrefix(line, " "):
+			errs[len(errs)-1] += "\n" + line
+		default:
+			inError = false
+		}
+	}
+	return errs
+}
+
+// captureOutput runs fn with stdout redirected and returns what it printed
+func captureOutput(fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		fn()
+		return ""
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	fn()
+	w.Close()
+	os.Stdout = stdout
+	return <-done
+}
+
+// verifyEnv returns the environment go commands run in: unless online,
+// modules are only read from the local module cache
+func verifyEnv(online bool) []string {
+	env := append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local")
+	if online {
+		return env
+	}
+	return append(env, "GOPROXY=off", "GOSUMDB=off")
+}
+
+// writeVerifyModule writes the go.mod of the generated module. Every module
+// imported by the generated code is required at the version the current
+// project uses, or else at the newest release in the local module cache.
+func writeVerifyModule(root, projectDir string) error {
+	imports := map[string]bool{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".go") {
+			return err
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), p, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil
+		}
+		for _, imp := range f.Imports {
+			importPath, _ := strconv.Unquote(imp.Path.Value)
+			if first, _, _ := strings.Cut(importPath, "/"); strings.Contains(first, ".") {
+				imports[importPath] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	projectRequires := goModRequirements(filepath.Join(projectDir, "go.mod"))
+	cache, goVersion := "", strings.TrimPrefix(runtime.Version(), "go")
+	if out, err := exec.Command("go", "env", "GOMODCACHE", "GOVERSION").Output(); err == nil {
+		if lines := strings.Fields(string(out)); len(lines) == 2 {
+			cache = filepath.Join(lines[0], "cache", "download")
+			goVersion = strings.TrimPrefix(lines[1], "go")
+		}
+	}
+
+	requires := map[string]string{}
+	for importPath := range imports {
+		// The module is the longest prefix of the import path with a known version
+		for mod := importPath; mod != "." && mod != ""; mod = path.Dir(mod) {
+			if version, ok := projectRequires[mod]; ok {
+				requires[mod] = version
+				break
+			}
+			if version := newestCachedVersion(cache, mod); version != "" {
+				requires[mod] = version
+				break
+			}
+		}
+	}
+
+	mods := make([]string, 0, len(requires))
+	for mod := range requires {
+		mods = append(mods, mod)
+	}
+	sort.Strings(mods)
+	var goMod strings.Builder
+	goMod.WriteString("module verify\n\ngo " + goVersion + "\n")
+	if len(mods) > 0 {
+		goMod.WriteString("\nrequire (\n")
+		for _, mod := range mods {
+			fmt.Fprintf(&goMod, "\t%s %s\n", mod, requires[mod])
+		}
+		goMod.WriteString(")\n")
+	}
+	return os.WriteFile(filepath.Join(root, "go.mod"), []byte(goMod.String()), 0644)
+}
+
+// goModRequirements reads the required module versions of a go.mod file
+func goModRequirements(goModPath string) map[string]string {
+	requires := map[string]string{}
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		return requires
+	}
+	file, err := modfile.Parse(goModPath, content, nil)
+	if err != nil {
+		return requires
+	}
+	for _, require := range file.Require {
+		requires[require.Mod.Path] = require.Mod.Version
+	}
+	return requires
+}
+
+// newestCachedVersion returns the newest release of mod downloaded to the
+// module cache, or "" when there is none
+func newestCachedVersion(cache, mod string) string {
+	if cache == "" {
+		return ""
+	}
+	// Upper case letters are escaped in the cache ("Masterminds" -> "!masterminds")
+	var escaped strings.Builder
+	for _, r := range mod {
+		if unicode.IsUpper(r) {
+			escaped.WriteString("!" + string(unicode.ToLower(r)))
+		} else {
+			escaped.WriteRune(r)
+		}
+	}
+
+	zips, _ := filepath.Glob(filepath.Join(cache, filepath.FromSlash(escaped.String()), "@v", "v*.zip"))
+	newest := ""
+	for _, zip := range zips {
+		version := strings.TrimSuffix(filepath.Base(zip), ".zip")
+		if strings.Contains(version, "-") || strings.Contains(version, "+") {
+			continue // pre-releases and pseudo-versions
+		}
+		if newest == "" || compareReleases(version, newest) > 0 {
+			newest = version
+		}
+	}
+	return newest
+}
+
+// compareReleases compares two release versions such as "v1.10.2"
+func compareReleases(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			return x - y
+		}
+	}
+	return len(as) - len(bs)
+}
+
+// runGo runs a go command in dir and returns its combined output
+func runGo(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// copyDir copies the files of src into dst; a missing src is not an error
+func copyDir(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(src, e.Name()))
+		if err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(dst, e.Name()), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
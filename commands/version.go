@@ -15,36 +15,80 @@ func VersionCommand() {
 // ShowHelp displays help information
 func ShowHelp() {
 	fmt.Println(dim + "Kullanım:" + reset)
-	fmt.Println("  " + bold + "went" + reset + " [command] [arguments]\n")
+	fmt.Print("  " + bold + "went" + reset + " [command] [arguments]\n\n")
 
 	fmt.Println(dim + "Proje Komutları:" + reset)
 	fmt.Println("  new                    Yeni proje oluştur (interaktif)")
 	fmt.Println("  new --name <name>      Proje adı ile yeni proje oluştur")
 	fmt.Println("      --template <type>  Şablon türü (API|CLI|API+CLI|API+ReactJS|API+ReactJS+CLI)")
 	fmt.Println("      --deployment <type> Dağıtım türü (Docker|Kubernetes|No-Deployment)")
-	fmt.Println("      --router <type>    Router türü (Gin|Chi) - varsayılan: Gin\n")
+	fmt.Print("      --router <type>    Router türü (Gin|Chi) - varsayılan: Gin\n\n")
 
 	fmt.Println(dim + "Template Üretimi:" + reset)
 	fmt.Println("  make:model <name>      Model dosyası oluştur")
-	fmt.Println("  make:controller <name> Controller dosyası oluştur (.env ROUTER değerine göre)")
-	fmt.Println("  make:middleware <name> Middleware dosyası oluştur")
+	fmt.Println("  make:controller <name> Controller dosyası oluştur (.env ROUTER değerine göre; ?sort=-created_at, ?filter[alan][op]=, PATCH merge/JSON Patch, POST/PATCH /batch, CSV/JSONL export/import)")
+	fmt.Println("  make:middleware <name> Middleware dosyası oluştur (.env ROUTER değerine göre)")
 	fmt.Println("  make:service <name>    Service dosyası oluştur")
-	fmt.Println("  make:migration <name>  Migration dosyası oluştur\n")
+	fmt.Println("  make:policy <name>     Yetkilendirme policy dosyası oluştur")
+	fmt.Println("  make:from-openapi <spec> OpenAPI dokümanından model, DTO, controller ve route oluştur")
+	fmt.Println("  make:migration <name>  Migration dosyası oluştur")
+	fmt.Println("      Admin/User         İsimler alt paket oluşturabilir (app/controllers/admin, route: /admin/users)")
+	fmt.Println("      --table <name>     Tablo adı - varsayılan: modelin snake_case çoğulu (Category -> categories)")
+	fmt.Println("      --fields \"a:tip[:kural] ...\" Model alanları (title:string:required price:decimal) - varsayılan: name, description")
+	fmt.Println("      --pk int|uuid|ulid  Birincil anahtar tipi - varsayılan: wentconfig.json primary_key, yoksa int")
+	fmt.Println("      --pagination offset|cursor Liste sayfalama (cursor: next_cursor/prev_cursor) - varsayılan: wentconfig.json pagination, yoksa offset")
+	fmt.Println("      --versioned        version sütunu ile iyimser kilitleme (ETag, If-Match -> 412, If-None-Match -> 304)")
+	fmt.Println("      --belongs-to, --has-many, --many-to-many \"[ad:]Model,...\" İlişkiler (?include= ve /posts/{id}/comments)")
+	fmt.Println("      --force            Var olan dosyanın üzerine yaz (.orig yedeği alınır)")
+	fmt.Print("      --merge            Var olan dosyayı yeni şablonla üç yönlü birleştir\n\n")
+
+	if generators := customGenerators(); len(generators) > 0 {
+		fmt.Println(dim + "Proje Üreticileri (wentconfig.json):" + reset)
+		for _, g := range generators {
+			description := g.Description
+			if description == "" {
+				description = g.Output
+			}
+			fmt.Printf("  %-22s %s\n", g.Usage(), description)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println(dim + "Silme:" + reset)
+	fmt.Println("  destroy:model <name>   Model dosyasını sil (destroy:controller, :service, :middleware, :policy)")
+	fmt.Println("  destroy:resource <name> Model, controller, service ve policy dosyalarını birlikte sil")
+	fmt.Print("      --force            Üretildikten sonra değiştirilmiş dosyaları da sil\n\n")
+
+	fmt.Println(dim + "API Dokümantasyonu:" + reset)
+	fmt.Println("  openapi:generate       Model ve controller'lardan openapi.yaml oluştur")
+	fmt.Println("      --output <file>    Çıktı dosyası - varsayılan: openapi.yaml")
+	fmt.Print("      --ui               app/docs altında Swagger UI/Redoc sunucusu oluştur\n\n")
+
+	fmt.Println(dim + "Şablonlar:" + reset)
+	fmt.Println("  templates:list         Şablonları ve nereden okunduklarını listele")
+	fmt.Println("  templates:publish <name> Gömülü şablonu .went/templates altına kopyala (--all, --global, --force)")
+	fmt.Println("      Öncelik: .went/templates > ~/.config/went/templates > gömülü şablonlar")
+	fmt.Print("      Fonksiyonlar: plural, singular, pascal, camel, snake, kebab, lowerFirst, receiver, now, uuid\n\n")
 
 	fmt.Println(dim + "Paket Yönetimi:" + reset)
-	fmt.Println("  pkg:install <url> <name>  Git reposundan paket indir")
+	fmt.Println("  pkg:install <url> <name>  Git reposundan paket indir (--force ile yeniden kur)")
 	fmt.Println("  pkg:list                  Kurulu paketleri listele")
 	fmt.Println("  pkg:remove <name>         Paketi kaldır")
-	fmt.Println("  pkg:update <name> <dir>   Import yollarını güncelle\n")
+	fmt.Print("  pkg:update <name> <dir>   Import yollarını güncelle\n\n")
 
 	fmt.Println(dim + "Router Konfigürasyonu:" + reset)
 	fmt.Println("  .env dosyasında ROUTER=gin veya ROUTER=chi")
 	fmt.Println("  Varsayılan: gin (eğer .env yoksa veya ROUTER boşsa)")
-	fmt.Println("  Controller üretimi .env ROUTER değerine göre yapılır\n")
+	fmt.Print("  Controller üretimi .env ROUTER değerine göre yapılır\n\n")
+
+	fmt.Println(dim + "Genel Seçenekler:" + reset)
+	fmt.Print("  --dry-run              Dosya yazmadan oluşturulacak/değişecek dosyaları diff ile göster\n\n")
 
 	fmt.Println(dim + "Diğer Komutlar:" + reset)
+	fmt.Println("  status                 Üretilen dosyaların durumunu göster (değişmemiş / özelleştirilmiş)")
+	fmt.Println("  verify                 Tüm şablon kombinasyonlarını geçici modülde üret ve go vet ile denetle (--keep, --online)")
 	fmt.Println("  version                Versiyon bilgisini göster")
-	fmt.Println("  help                   Bu yardım mesajını göster\n")
+	fmt.Print("  help                   Bu yardım mesajını göster\n\n")
 
 	fmt.Println(dim + "Örnekler:" + reset)
 	fmt.Println("  went new --name my-app --template API --deployment Docker --router chi")
@@ -52,6 +96,7 @@ func ShowHelp() {
 	fmt.Println("  echo \"ROUTER=chi\" > .env && went make:controller Product")
 	fmt.Println("  went pkg:install https://github.com/gin-gonic/gin gin")
 	fmt.Println("  went pkg:list")
+	fmt.Println("  went make:model User --dry-run")
 	fmt.Println()
 }
 
@@ -0,0 +1,281 @@
+package commands
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"went-plate/internal/diff"
+	"went-plate/internal/embedded"
+	"went-plate/internal/manifest"
+)
+
+// DryRun makes every command render to memory and print the files it would
+// create or modify, with unified diffs, instead of touching the disk
+var DryRun bool
+
+// forceOverwrite and mergeExisting decide what happens when a generated
+// file already exists; by default it is skipped
+var (
+	forceOverwrite bool
+	mergeExisting  bool
+)
+
+// baseDir stores the originally generated version of every file, used as
+// the common ancestor when merging template changes with user edits
+const baseDir = ".went/base"
+
+// dryRunSummary counts the changes reported during a dry run
+var dryRunSummary struct {
+	created, modified, unchanged int
+}
+
+// writeFile writes content to path, creating parent directories as needed.
+// In dry-run mode it prints a unified diff against the current content instead.
+func writeFile(path string, content []byte) error {
+	if DryRun {
+		previewChange(path, content)
+		return nil
+	}
+
+	if err := os.MkdirAll(GetDir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// addOverwriteFlags registers --force and --merge on a command's flag set
+func addOverwriteFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&forceOverwrite, "force", false, "Overwrite existing files (a .orig backup is kept)")
+	fs.BoolVar(&mergeExisting, "merge", false, "Three-way merge existing files with the regenerated output")
+}
+
+// writeOutcome is what writeGeneratedFile did with a file
+type writeOutcome int
+
+const (
+	fileSkipped    writeOutcome = iota // left as it was: existing, unchanged or failed
+	fileWritten                        // created, or overwritten with --force
+	fileMerged                         // template changes merged in with --merge
+	fileConflicted                     // merged with --merge, leaving conflict markers
+)
+
+// writeGeneratedFile writes content rendered from templateName to outputPath
+// and records it in the manifest. Existing files are skipped unless --force
+// (overwrite with a .orig backup) or --merge (three-way merge against the
+// originally generated version) was given. Merges are reported here; callers
+// report written files.
+func writeGeneratedFile(outputPath, templateName string, content []byte) writeOutcome {
+	existing, err := os.ReadFile(outputPath)
+	if err != nil {
+		if err := writeFile(outputPath, content); err != nil {
+			fmt.Printf("%s[ERROR]%s Failed to write %s: %v\n", red, reset, outputPath, err)
+			return fileSkipped
+		}
+		recordGenerated(outputPath, templateName, content)
+		return fileWritten
+	}
+
+	switch {
+	case mergeExisting:
+		base, err := os.ReadFile(basePath(outputPath))
+		if err != nil {
+			fmt.Printf("%s[ERROR]%s No originally generated version of %s is recorded; use --force instead\n", red, reset, outputPath)
+			return fileSkipped
+		}
+
+		lines, conflicts := diff.Merge3(diff.SplitLines(base), diff.SplitLines(existing), diff.SplitLines(content))
+		merged := []byte(strings.Join(lines, "\n") + "\n")
+		if bytes.Equal(merged, existing) {
+			fmt.Printf("Unchanged: %s\n", outputPath)
+			recordGenerated(outputPath, templateName, content)
+			return fileSkipped
+		}
+		if !backupFile(outputPath, existing) || writeFile(outputPath, merged) != nil {
+			return fileSkipped
+		}
+		recordGenerated(outputPath, templateName, content)
+
+		if conflicts > 0 {
+			fmt.Printf("%s[WARN]%s Merged %s with %d conflict(s); resolve the %s markers\n", yellow, reset, outputPath, conflicts, diff.MarkerOurs)
+			return fileConflicted
+		}
+		if !DryRun {
+			fmt.Printf("%s[OK]%s Merged template changes into %s\n", green, reset, outputPath)
+		}
+		return fileMerged
+
+	case forceOverwrite:
+		if bytes.Equal(existing, content) {
+			// Regenerated as it is: still recorded, as generated by this run
+			fmt.Printf("Unchanged: %s\n", outputPath)
+			recordGenerated(outputPath, templateName, content)
+			return fileSkipped
+		}
+		if !backupFile(outputPath, existing) || writeFile(outputPath, content) != nil {
+			return fileSkipped
+		}
+		recordGenerated(outputPath, templateName, content)
+		return fileWritten
+
+	default:
+		fmt.Printf("Skipped (already exists): %s (use --force or --merge)\n", outputPath)
+		return fileSkipped
+	}
+}
+
+// backupFile saves the current content of path to path.orig
+func backupFile(path string, content []byte) bool {
+	if DryRun {
+		fmt.Printf("%s[DRY-RUN]%s would back up %s to %s.orig\n", cyan, reset, path, path)
+		return true
+	}
+	if err := os.WriteFile(path+".orig", content, 0644); err != nil {
+		fmt.Printf("%s[ERROR]%s Failed to back up %s: %v\n", red, reset, path, err)
+		return false
+	}
+	fmt.Printf("%s[INFO]%s Backed up %s to %s.orig\n", blue, reset, path, path)
+	return true
+}
+
+// basePath returns where the originally generated version of path is kept
+func basePath(path string) string {
+	return filepath.Join(baseDir, filepath.Clean(path))
+}
+
+// recordGenerated remembers the rendered content of a generated file in
+// .went/base and records its hash and origin in the manifest
+func recordGenerated(path, templateName string, content []byte) {
+	if DryRun {
+		return
+	}
+	target := basePath(path)
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err == nil {
+		os.WriteFile(target, content, 0644)
+	}
+
+	m, err := manifest.Load(manifest.Path)
+	if err != nil {
+		fmt.Printf("%s[WARN]%s %v; %s was not recorded\n", yellow, reset, err, path)
+		return
+	}
+	m.Files[filepath.ToSlash(filepath.Clean(path))] = manifest.Entry{
+		Generator:       generatorCommand(),
+		Inputs:          generatorInputs(),
+		Template:        templateName,
+		TemplateVersion: templateVersion(templateName),
+		WentVersion:     Version,
+		SHA256:          manifest.Hash(content),
+		GeneratedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+	if err := m.Save(manifest.Path); err != nil {
+		fmt.Printf("%s[WARN]%s Failed to update %s: %v\n", yellow, reset, manifest.Path, err)
+	}
+}
+
+// forgetGenerated removes a deleted file from .went/base and the manifest
+func forgetGenerated(path string) {
+	if DryRun {
+		return
+	}
+	os.Remove(basePath(path))
+
+	m, err := manifest.Load(manifest.Path)
+	if err != nil {
+		return
+	}
+	key := filepath.ToSlash(filepath.Clean(path))
+	if _, ok := m.Files[key]; ok {
+		delete(m.Files, key)
+		m.Save(manifest.Path)
+	}
+}
+
+// generatorCommand returns the command that is running, e.g. "make:model"
+func generatorCommand() string {
+	if len(os.Args) < 2 {
+		return ""
+	}
+	return os.Args[1]
+}
+
+// generatorInputs returns the arguments of the running generator, without the
+// flags that only decide how existing files are treated
+func generatorInputs() []string {
+	if len(os.Args) < 3 {
+		return nil
+	}
+	var inputs []string
+	for _, arg := range os.Args[2:] {
+		switch arg {
+		case "--force", "-force", "--merge", "-merge":
+			continue
+		}
+		inputs = append(inputs, arg)
+	}
+	return inputs
+}
+
+// templateVersion identifies the current source of a template by its hash
+func templateVersion(templateName string) string {
+	source, err := embedded.GetTemplate(templateName)
+	if err != nil {
+		return ""
+	}
+	return manifest.Hash(source)
+}
+
+// previewChange prints what writing content to path would change
+func previewChange(path string, content []byte) {
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		existing = nil
+	}
+
+	patch := diff.Unified(path, existing, content)
+	switch {
+	case patch == "" && existing != nil:
+		dryRunSummary.unchanged++
+		fmt.Printf("%s[DRY-RUN]%s unchanged: %s\n", cyan, reset, path)
+		return
+	case existing == nil:
+		dryRunSummary.created++
+		fmt.Printf("%s[DRY-RUN]%s would create: %s\n", cyan, reset, path)
+	default:
+		dryRunSummary.modified++
+		fmt.Printf("%s[DRY-RUN]%s would modify: %s\n", cyan, reset, path)
+	}
+	printDiff(patch)
+}
+
+// printDiff prints a unified diff with colored additions and deletions
+func printDiff(patch string) {
+	for _, line := range strings.Split(strings.TrimSuffix(patch, "\n"), "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			fmt.Println(bold + line + reset)
+		case strings.HasPrefix(line, "@@"):
+			fmt.Println(cyan + line + reset)
+		case strings.HasPrefix(line, "+"):
+			fmt.Println(green + line + reset)
+		case strings.HasPrefix(line, "-"):
+			fmt.Println(red + line + reset)
+		default:
+			fmt.Println(line)
+		}
+	}
+}
+
+// PrintDryRunSummary reports the totals of a dry run
+func PrintDryRunSummary() {
+	if !DryRun {
+		return
+	}
+	fmt.Printf("\n%s[DRY-RUN]%s %d file(s) would be created, %d modified, %d unchanged. Nothing was written.\n",
+		cyan, reset, dryRunSummary.created, dryRunSummary.modified, dryRunSummary.unchanged)
+}
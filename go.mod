@@ -2,7 +2,11 @@ module went-plate
 
 go 1.24.4
 
-require github.com/manifoldco/promptui v0.9.0
+require (
+	github.com/manifoldco/promptui v0.9.0
+	golang.org/x/mod v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e // indirect
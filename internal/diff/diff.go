@@ -0,0 +1,181 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpKind is the kind of a line in an edit script
+type OpKind byte
+
+const (
+	Equal  OpKind = ' '
+	Delete OpKind = '-'
+	Insert OpKind = '+'
+)
+
+// Op is a single line of an edit script
+type Op struct {
+	Kind OpKind
+	Line string
+}
+
+// SplitLines splits content into lines without their trailing newline
+func SplitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	s := strings.TrimSuffix(string(content), "\n")
+	return strings.Split(s, "\n")
+}
+
+// Lines computes a minimal line-based edit script turning a into b
+func Lines(a, b []string) []Op {
+	// Trim the common prefix and suffix to keep the LCS table small
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []Op
+	for _, line := range a[:prefix] {
+		ops = append(ops, Op{Equal, line})
+	}
+
+	x, y := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(x), len(y)
+
+	// lcs[i][j] is the LCS length of x[i:] and y[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case x[i] == y[j]:
+			ops = append(ops, Op{Equal, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, Op{Delete, x[i]})
+			i++
+		default:
+			ops = append(ops, Op{Insert, y[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, Op{Delete, x[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, Op{Insert, y[j]})
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, Op{Equal, line})
+	}
+	return ops
+}
+
+// Unified returns a unified diff between old and new content with three
+// lines of context. A nil old means the file is being created.
+// The result is empty when both contents are identical.
+func Unified(path string, old, new []byte) string {
+	a, b := SplitLines(old), SplitLines(new)
+	ops := Lines(a, b)
+
+	changed := false
+	for _, op := range ops {
+		if op.Kind != Equal {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var out strings.Builder
+	if old == nil {
+		out.WriteString("--- /dev/null\n")
+	} else {
+		fmt.Fprintf(&out, "--- a/%s\n", path)
+	}
+	fmt.Fprintf(&out, "+++ b/%s\n", path)
+
+	const context = 3
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].Kind == Equal {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are within 2*context lines of each other
+		end := start
+		for k := start; k < len(ops); k++ {
+			if ops[k].Kind != Equal {
+				end = k + 1
+			} else if k-end >= 2*context {
+				break
+			}
+		}
+
+		from := max(start-context, 0)
+		to := min(end+context, len(ops))
+
+		// Line numbers are derived from the ops preceding the hunk
+		aStart, bStart := 1, 1
+		for _, op := range ops[:from] {
+			if op.Kind != Insert {
+				aStart++
+			}
+			if op.Kind != Delete {
+				bStart++
+			}
+		}
+		aCount, bCount := 0, 0
+		for _, op := range ops[from:to] {
+			if op.Kind != Insert {
+				aCount++
+			}
+			if op.Kind != Delete {
+				bCount++
+			}
+		}
+		if aCount == 0 {
+			aStart--
+		}
+		if bCount == 0 {
+			bStart--
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, op := range ops[from:to] {
+			out.WriteByte(byte(op.Kind))
+			out.WriteString(op.Line)
+			out.WriteByte('\n')
+		}
+		start = to
+	}
+	return out.String()
+}
@@ -0,0 +1,134 @@
+package diff
+
+// Conflict markers written around regions changed on both sides
+const (
+	MarkerOurs   = "<<<<<<< yours"
+	MarkerSep    = "======="
+	MarkerTheirs = ">>>>>>> regenerated"
+)
+
+// hunk replaces base[start:end] with lines
+type hunk struct {
+	start, end int
+	lines      []string
+}
+
+// hunks groups an edit script into replacement hunks over the base lines
+func hunks(ops []Op) []hunk {
+	var result []hunk
+	var cur *hunk
+	pos := 0
+
+	for _, op := range ops {
+		switch op.Kind {
+		case Equal:
+			if cur != nil {
+				result = append(result, *cur)
+				cur = nil
+			}
+			pos++
+		case Delete:
+			if cur == nil {
+				cur = &hunk{start: pos, end: pos}
+			}
+			cur.end++
+			pos++
+		case Insert:
+			if cur == nil {
+				cur = &hunk{start: pos, end: pos}
+			}
+			cur.lines = append(cur.lines, op.Line)
+		}
+	}
+	if cur != nil {
+		result = append(result, *cur)
+	}
+	return result
+}
+
+// apply returns base[start:end] with the given hunks applied
+func apply(base []string, hs []hunk, start, end int) []string {
+	var result []string
+	pos := start
+	for _, h := range hs {
+		result = append(result, base[pos:h.start]...)
+		result = append(result, h.lines...)
+		pos = h.end
+	}
+	return append(result, base[pos:end]...)
+}
+
+// Merge3 performs a line-based three-way merge of ours and theirs, both
+// derived from base. Regions changed differently on both sides are emitted
+// between conflict markers. It returns the merged lines and the number of conflicts.
+func Merge3(base, ours, theirs []string) ([]string, int) {
+	a := hunks(Lines(base, ours))
+	b := hunks(Lines(base, theirs))
+
+	var out []string
+	conflicts := 0
+	pos, i, j := 0, 0, 0
+
+	for i < len(a) || j < len(b) {
+		start := 0
+		if j >= len(b) || (i < len(a) && a[i].start <= b[j].start) {
+			start = a[i].start
+		} else {
+			start = b[j].start
+		}
+
+		// Grow the region while hunks from either side touch it
+		end := start
+		ai, bj := i, j
+		for {
+			if ai < len(a) && a[ai].start <= end {
+				end = max(end, a[ai].end)
+				ai++
+				continue
+			}
+			if bj < len(b) && b[bj].start <= end {
+				end = max(end, b[bj].end)
+				bj++
+				continue
+			}
+			break
+		}
+
+		out = append(out, base[pos:start]...)
+		oursPart := apply(base, a[i:ai], start, end)
+		theirsPart := apply(base, b[j:bj], start, end)
+
+		switch {
+		case bj == j:
+			out = append(out, oursPart...)
+		case ai == i:
+			out = append(out, theirsPart...)
+		case equalLines(oursPart, theirsPart):
+			out = append(out, oursPart...)
+		default:
+			conflicts++
+			out = append(out, MarkerOurs)
+			out = append(out, oursPart...)
+			out = append(out, MarkerSep)
+			out = append(out, theirsPart...)
+			out = append(out, MarkerTheirs)
+		}
+
+		pos, i, j = end, ai, bj
+	}
+
+	return append(out, base[pos:]...), conflicts
+}
+
+// equalLines reports whether two line slices are identical
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -4,6 +4,9 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -12,8 +15,111 @@ import (
 //go:embed templates/*.tpl
 var TemplateFS embed.FS
 
-// GetTemplate reads a template file from the embedded filesystem
+// ProjectTemplateDir holds project-local template overrides
+const ProjectTemplateDir = ".went/templates"
+
+// Embedded is reported as the source of templates read from the binary
+const Embedded = "embedded"
+
+// OverrideDirs returns the directories searched for template overrides, in
+// order of precedence: the project's .went/templates, then the user's
+// ~/.config/went/templates
+func OverrideDirs() []string {
+	dirs := []string{ProjectTemplateDir}
+	if dir := UserTemplateDir(); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// UserTemplateDir returns the user-level override directory
+// ($XDG_CONFIG_HOME/went/templates, defaulting to ~/.config/went/templates)
+func UserTemplateDir() string {
+	if config := os.Getenv("XDG_CONFIG_HOME"); config != "" {
+		return filepath.Join(config, "went", "templates")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "went", "templates")
+}
+
+// GetTemplate reads a template, preferring overrides over the embedded version
 func GetTemplate(name string) ([]byte, error) {
+	content, _, err := ResolveTemplate(name)
+	return content, err
+}
+
+// ResolveTemplate reads a template and reports where it was found: the path
+// of an override file or Embedded. Names containing a slash below the
+// templates/ prefix (e.g. "generators/dto.tpl") are read as project files.
+func ResolveTemplate(name string) ([]byte, string, error) {
+	base := strings.TrimSuffix(strings.TrimPrefix(name, "templates/"), ".tpl")
+	if strings.ContainsAny(base, `/\`) {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return nil, "", fmt.Errorf("template file '%s' not found", name)
+		}
+		return content, name, nil
+	}
+	base = CurrentName(base)
+	for _, dir := range OverrideDirs() {
+		path := filepath.Join(dir, base+".tpl")
+		if content, err := os.ReadFile(path); err == nil {
+			return content, path, nil
+		}
+		if content, path, err := formerOverride(dir, base); content != nil || err != nil {
+			return content, path, err
+		}
+	}
+
+	content, err := GetEmbeddedTemplate(base)
+	return content, Embedded, err
+}
+
+// renamedTemplates maps former template names to the templates that replaced
+// them. Overrides still saved under a former name apply to the first
+// replacement, which renders what the former template did.
+var renamedTemplates = map[string][]string{
+	"middleware": {"middleware_gin", "middleware_chi"},
+}
+
+// CurrentName returns the name that replaced a former template name, or name
+// itself
+func CurrentName(name string) string {
+	if replacements, ok := renamedTemplates[name]; ok {
+		return replacements[0]
+	}
+	return name
+}
+
+// formerOverride reads the override of dir saved under the former name of
+// template base. Other replacements than the first fail rather than ignore
+// it, since the override was written for the template the first one replaced.
+func formerOverride(dir, base string) ([]byte, string, error) {
+	for former, replacements := range renamedTemplates {
+		for i, replacement := range replacements {
+			if replacement != base {
+				continue
+			}
+			path := filepath.Join(dir, former+".tpl")
+			if _, err := os.Stat(path); err != nil {
+				return nil, "", nil
+			}
+			if i > 0 {
+				return nil, "", fmt.Errorf("template '%s' was renamed to '%s'; rename %s to %s.tpl, or to %s.tpl once it is written for %s", former, replacements[0], path, replacements[0], base, base)
+			}
+			content, err := os.ReadFile(path)
+			return content, path, err
+		}
+	}
+	return nil, "", nil
+}
+
+// GetEmbeddedTemplate reads a template file from the embedded filesystem,
+// ignoring overrides
+func GetEmbeddedTemplate(name string) ([]byte, error) {
 	// Ensure the template name has the correct path and extension
 	templateName := name
 	if !strings.HasPrefix(templateName, "templates/") {
@@ -31,9 +137,10 @@ func GetTemplate(name string) ([]byte, error) {
 	return content, nil
 }
 
-// ListTemplates returns a list of all available templates
+// ListTemplates returns the names of all available templates: the embedded
+// ones and those only defined as overrides
 func ListTemplates() ([]string, error) {
-	var templates []string
+	seen := map[string]bool{}
 
 	err := fs.WalkDir(TemplateFS, "templates", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -43,15 +150,35 @@ func ListTemplates() ([]string, error) {
 			// Remove the "templates/" prefix and ".tpl" suffix
 			templateName := strings.TrimPrefix(path, "templates/")
 			templateName = strings.TrimSuffix(templateName, ".tpl")
-			templates = append(templates, templateName)
+			seen[templateName] = true
 		}
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return templates, err
+	for _, dir := range OverrideDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".tpl") {
+				seen[strings.TrimSuffix(entry.Name(), ".tpl")] = true
+			}
+		}
+	}
+
+	templates := make([]string, 0, len(seen))
+	for name := range seen {
+		templates = append(templates, name)
+	}
+	sort.Strings(templates)
+	return templates, nil
 }
 
-// TemplateExists checks if a template exists in the embedded filesystem
+// TemplateExists checks if a template exists as an override or in the embedded filesystem
 func TemplateExists(name string) bool {
 	_, err := GetTemplate(name)
 	return err == nil
@@ -0,0 +1,52 @@
+// Package batch reports the outcome of every item of the generated batch
+// endpoints, so that a failing item doesn't hide what happened to the others.
+package batch
+
+import (
+	"log"
+	"net/http"
+
+	"{{.ModulePath}}/app/httperr"
+)
+
+// MaxSize is the default number of items a batch request may carry; the
+// MaxBatchSize of each controller starts from it
+var MaxSize = {{if gt .Config.BatchSize 0}}{{.Config.BatchSize}}{{else}}100{{end}}
+
+// InsertSize is the number of rows inserted per statement by batch creates
+const InsertSize = 100
+
+// Result is the outcome of a single item, in the order of the request
+type Result struct {
+	Index  int                 `json:"index"`
+	Status int                 `json:"status"`
+	Data   interface{}         `json:"data,omitempty"`
+	Detail string              `json:"detail,omitempty"`
+	Errors map[string][]string `json:"errors,omitempty"` // validation messages by JSON field
+}
+
+// Succeeded returns the result of an item processed with the given status
+func Succeeded(index, status int, data interface{}) Result {
+	return Result{Index: index, Status: status, Data: data}
+}
+
+// Failed returns the result of an item that failed with err, mapped to a
+// status like httperr.Write does, with validation messages in lang
+func Failed(index int, err error, lang string) Result {
+	problem := httperr.FromError(err, lang)
+	if problem.Status == http.StatusInternalServerError {
+		log.Printf("[ERROR] batch item %d: %v", index, err)
+	}
+	return Result{Index: index, Status: problem.Status, Detail: problem.Detail, Errors: problem.Errors}
+}
+
+// Status returns the status of a whole batch: success when every item
+// succeeded, 207 Multi-Status otherwise
+func Status(results []Result, success int) int {
+	for _, result := range results {
+		if result.Status >= http.StatusBadRequest {
+			return http.StatusMultiStatus
+		}
+	}
+	return success
+}
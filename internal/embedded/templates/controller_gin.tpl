@@ -1,52 +1,156 @@
-package controllers
+package {{or .Package "controllers"}}
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
 
-	"github.com/gin-gonic/gin"
+	{{if eq .PrimaryKey "uuid"}}"github.com/google/uuid"
+	{{else if eq .PrimaryKey "ulid"}}"github.com/oklog/ulid/v2"
+	{{end}}"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
-	"{{.ProjectName}}/app/models"
+	"{{.ModulePath}}/app/batch"
+	"{{.ModulePath}}/app/httperr"
+	"{{.ModulePath}}/app/patch"
+	"{{.ModulePath}}/app/query"
+	"{{.ModulePath}}/app/transfer"
+	{{.ModelsImport}}{{if .HasPolicy}}
+	{{.PoliciesImport}}{{end}}
 )
 
+// {{.ModelName}}Policy authorizes actions on {{.ModelName}} records.
+// Generate an implementation with `went make:policy {{.ModelName}}`.
+type {{.ModelName}}Policy interface {
+	CanView(user interface{}, {{.VarName}} *models.{{.ModelName}}) bool
+	CanCreate(user interface{}, {{.VarName}} *models.{{.ModelName}}) bool
+	CanUpdate(user interface{}, {{.VarName}} *models.{{.ModelName}}) bool
+	CanDelete(user interface{}, {{.VarName}} *models.{{.ModelName}}) bool
+}
+
 // {{.ModelName}}Controller handles {{.ModelName}} related requests
 type {{.ModelName}}Controller struct {
-	DB *gorm.DB
+	DB           *gorm.DB
+	Policy       {{.ModelName}}Policy // nil allows every action
+	MaxBatchSize int                  // items accepted by BatchStore and BatchUpdate
 }
 
 // New{{.ModelName}}Controller creates a new {{.ModelName}}Controller instance
 func New{{.ModelName}}Controller(db *gorm.DB) *{{.ModelName}}Controller {
-	return &{{.ModelName}}Controller{DB: db}
+	return &{{.ModelName}}Controller{DB: db, MaxBatchSize: batch.MaxSize{{if .HasPolicy}}, Policy: policies.New{{.ModelName}}Policy(){{end}}}
 }
 
-// Index returns all {{.ModelName}}s with pagination and search
-// GET /{{.TableName}}?page=1&limit=10&search=query&order_by=created_at
+{{if eq .Pagination "cursor" -}}
+// Index returns {{.PluralName}} a page at a time, continuing from the cursor of
+// a previous page; total=true adds the total count
+// GET /{{.RouteName}}?limit=10&search=query&sort=-created_at&filter[id][in]=1,2&cursor=...&total=true{{with .Relations}}&include={{(index . 0).Include}}{{end}}
+func (c *{{.ModelName}}Controller) Index(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if limit <= 0 {
+		limit = 10
+	}
+	search := ctx.Query("search")
+
+	if !c.authorize(ctx, "view", nil) {
+		return
+	}
+
+	keyset, err := query.NewKeyset(ctx.Query("sort"), ctx.Query("cursor"), models.{{.ModelName}}Columns, "-created_at")
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+	filter, err := query.Filter(ctx.Request.URL.Query(), models.{{.ModelName}}Columns)
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+{{- if .Relations}}
+
+	preload, err := models.Preload{{.ModelName}}Includes(ctx.Query("include"))
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+{{- end}}
+
+	{{.PluralVarName}}, next, prev, err := models.Page{{.PluralName}}(c.DB.Scopes(filter, models.Match{{.PluralName}}(search)), keyset, limit{{if .Relations}}, preload{{end}})
+	if err != nil {
+		if errors.Is(err, query.ErrInvalidCursor) {
+			err = httperr.BadRequest(err.Error())
+		}
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+
+	meta := gin.H{
+		"limit":       limit,
+		"next_cursor": next,
+		"prev_cursor": prev,
+	}
+	if ctx.Query("total") == "true" {
+		total, err := models.Count{{.PluralName}}(c.DB.Scopes(filter, models.Match{{.PluralName}}(search)))
+		if err != nil {
+			httperr.Write(ctx.Writer, ctx.Request, err)
+			return
+		}
+		meta["total_count"] = total
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": {{.PluralVarName}}, "meta": meta})
+}
+{{- else -}}
+// Index returns all {{.PluralName}} with pagination and search
+// GET /{{.RouteName}}?page=1&limit=10&search=query&sort=-created_at&filter[id][in]=1,2{{with .Relations}}&include={{(index . 0).Include}}{{end}}
 func (c *{{.ModelName}}Controller) Index(ctx *gin.Context) {
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
 	search := ctx.Query("search")
-	orderBy := ctx.DefaultQuery("order_by", "created_at DESC")
 	offset := (page - 1) * limit
 
-	var {{.TableName}} []models.{{.ModelName}}
+	if !c.authorize(ctx, "view", nil) {
+		return
+	}
+
+	orderBy, err := query.Sort(ctx.Query("sort"), models.{{.ModelName}}Columns, "created_at DESC")
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+	filter, err := query.Filter(ctx.Request.URL.Query(), models.{{.ModelName}}Columns)
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+
+	var {{.PluralVarName}} []models.{{.ModelName}}
 	var total int64
-	var err error
+{{- if .Relations}}
+
+	preload, err := models.Preload{{.ModelName}}Includes(ctx.Query("include"))
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+{{- end}}
 
 	if search != "" {
-		{{.TableName}}, total, err = models.Search{{.ModelName}}s(c.DB, search, limit, offset)
+		{{.PluralVarName}}, total, err = models.Search{{.PluralName}}(c.DB.Scopes(filter), search, limit, offset, orderBy{{if .Relations}}, preload{{end}})
 	} else {
-		{{.TableName}}, total, err = models.GetAll{{.ModelName}}s(c.DB, limit, offset, orderBy)
+		{{.PluralVarName}}, total, err = models.GetAll{{.PluralName}}(c.DB.Scopes(filter), limit, offset, orderBy{{if .Relations}}, preload{{end}})
 	}
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
 
 	totalPages := (int(total) + limit - 1) / limit
 	ctx.JSON(http.StatusOK, gin.H{
-		"data": {{.TableName}},
+		"data": {{.PluralVarName}},
 		"meta": gin.H{
 			"current_page": page,
 			"total_pages":  totalPages,
@@ -55,87 +159,213 @@ func (c *{{.ModelName}}Controller) Index(ctx *gin.Context) {
 		},
 	})
 }
+{{- end}}
 
 // Show returns a specific {{.ModelName}}
-// GET /{{.TableName}}/:id
+// GET /{{.RouteName}}/:id{{with .Relations}}?include={{(index . 0).Include}}{{end}}
 func (c *{{.ModelName}}Controller) Show(ctx *gin.Context) {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	id, err := c.parseID(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("Invalid ID"))
 		return
 	}
+{{- if .Relations}}
 
-	{{.ModelName}}, err := models.Get{{.ModelName}}ByID(c.DB, uint(id))
+	preload, err := models.Preload{{.ModelName}}Includes(ctx.Query("include"))
 	if err != nil {
-		status := http.StatusInternalServerError
-		if strings.Contains(err.Error(), "record not found") {
-			status = http.StatusNotFound
-		}
-		ctx.JSON(status, gin.H{"error": err.Error()})
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+{{- end}}
+
+	{{.VarName}}, err := models.Get{{.ModelName}}ByID(c.DB, id{{if .Relations}}, preload{{end}})
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+
+	if !c.authorize(ctx, "view", {{.VarName}}) {
+		return
+	}
+{{- if .Versioned}}
+
+	ctx.Header("ETag", {{.VarName}}.ETag())
+	if c.matchETag(ctx.GetHeader("If-None-Match"), {{.VarName}}.ETag(), true) {
+		ctx.Status(http.StatusNotModified)
 		return
 	}
+{{- end}}
 
-	ctx.JSON(http.StatusOK, gin.H{"data": {{.ModelName}}})
+	ctx.JSON(http.StatusOK, gin.H{"data": {{.VarName}}})
 }
 
 // Store creates a new {{.ModelName}}
-// POST /{{.TableName}}
+// POST /{{.RouteName}}
 func (c *{{.ModelName}}Controller) Store(ctx *gin.Context) {
-	var {{.ModelName}} models.{{.ModelName}}
+	var {{.VarName}} models.{{.ModelName}}
 
-	if err := ctx.ShouldBindJSON(&{{.ModelName}}); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := ctx.ShouldBindJSON(&{{.VarName}}); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
 		return
 	}
 
-	if err := {{.ModelName}}.Create(c.DB); err != nil {
-		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	if err := {{.VarName}}.Validate(); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{
-		"data":    {{.ModelName}},
+	if !c.authorize(ctx, "create", &{{.VarName}}) {
+		return
+	}
+
+	if err := {{.VarName}}.Create(c.DB); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+
+{{if .Versioned}}	ctx.Header("ETag", {{.VarName}}.ETag())
+{{end}}	ctx.JSON(http.StatusCreated, gin.H{
+		"data":    {{.VarName}},
 		"message": "{{.ModelName}} created successfully",
 	})
 }
 
 // Update updates an existing {{.ModelName}}
-// PUT /{{.TableName}}/:id
+// PUT /{{.RouteName}}/:id
 func (c *{{.ModelName}}Controller) Update(ctx *gin.Context) {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	id, err := c.parseID(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("Invalid ID"))
 		return
 	}
 
-	{{.ModelName}}, err := models.Get{{.ModelName}}ByID(c.DB, uint(id))
+	{{.VarName}}, err := models.Get{{.ModelName}}ByID(c.DB, id)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if strings.Contains(err.Error(), "record not found") {
-			status = http.StatusNotFound
-		}
-		ctx.JSON(status, gin.H{"error": err.Error()})
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
 
-	if err := ctx.ShouldBindJSON({{.ModelName}}); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !c.authorize(ctx, "update", {{.VarName}}) {
 		return
 	}
+{{- if .Versioned}}
 
-	if err := {{.ModelName}}.Update(c.DB); err != nil {
-		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	if !c.ifMatch(ctx, {{.VarName}}) {
 		return
 	}
+{{- end}}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"data":    {{.ModelName}},
+	original := *{{.VarName}}
+	if err := ctx.ShouldBindJSON({{.VarName}}); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+
+	// The body replaces the fields of the authorized record only: it can't
+	// move the update to another record or rewrite its history
+	if {{.VarName}}.ID != original.ID {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("The id of the body doesn't match the URL"))
+		return
+	}
+{{- if .Versioned}}
+	if {{.VarName}}.Version != original.Version {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.Conflict(models.Err{{.ModelName}}Stale.Error()))
+		return
+	}
+{{- end}}
+	{{.VarName}}.CreatedAt = original.CreatedAt
+	{{.VarName}}.DeletedAt = original.DeletedAt
+
+	if err := {{.VarName}}.Validate(); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+
+	if err := {{.VarName}}.Update(c.DB); err != nil {
+{{- if .Versioned}}
+		if errors.Is(err, models.Err{{.ModelName}}Stale) {
+			err = httperr.Conflict(err.Error())
+		}
+{{- end}}
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+
+{{if .Versioned}}	ctx.Header("ETag", {{.VarName}}.ETag())
+{{end}}	ctx.JSON(http.StatusOK, gin.H{
+		"data":    {{.VarName}},
 		"message": "{{.ModelName}} updated successfully",
 	})
 }
 
+// Patch applies a JSON Merge Patch (RFC 7396) or, sent as
+// application/json-patch+json, a JSON Patch (RFC 6902) to a {{.ModelName}};
+// only the fields it changes are written
+// PATCH /{{.RouteName}}/:id
+func (c *{{.ModelName}}Controller) Patch(ctx *gin.Context) {
+	id, err := c.parseID(ctx.Param("id"))
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("Invalid ID"))
+		return
+	}
+
+	{{.VarName}}, err := models.Get{{.ModelName}}ByID(c.DB, id)
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+
+	if !c.authorize(ctx, "update", {{.VarName}}) {
+		return
+	}
+{{- if .Versioned}}
+
+	if !c.ifMatch(ctx, {{.VarName}}) {
+		return
+	}
+{{- end}}
+
+	body, err := ctx.GetRawData()
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+
+	var patched models.{{.ModelName}}
+	fields, err := patch.Apply({{.VarName}}, &patched, body, ctx.ContentType(), models.{{.ModelName}}Columns)
+	if errors.Is(err, patch.ErrTestFailed) {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.Conflict(err.Error()))
+		return
+	}
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+
+	if err := patched.Validate(); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+
+	if err := patched.Patch(c.DB, fields); err != nil {
+{{- if .Versioned}}
+		if errors.Is(err, models.Err{{.ModelName}}Stale) {
+			err = httperr.Conflict(err.Error())
+		}
+{{- end}}
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+
+{{if .Versioned}}	ctx.Header("ETag", patched.ETag())
+{{end}}	ctx.JSON(http.StatusOK, gin.H{
+		"data":    patched,
+		"message": "{{.ModelName}} patched successfully",
+	})
+}
+
 // UpdateOrCreate updates an existing {{.ModelName}} or creates a new one
-// POST /{{.TableName}}/upsert
+// POST /{{.RouteName}}/upsert
 func (c *{{.ModelName}}Controller) UpdateOrCreate(ctx *gin.Context) {
 	var request struct {
 		Conditions map[string]interface{} `json:"conditions"`
@@ -143,47 +373,83 @@ func (c *{{.ModelName}}Controller) UpdateOrCreate(ctx *gin.Context) {
 	}
 
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
 		return
 	}
 
 	if len(request.Conditions) == 0 {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Conditions required"})
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("Conditions required"))
+		return
+	}
+	for field := range request.Conditions {
+		if _, ok := models.{{.ModelName}}Columns[field]; !ok {
+			httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("Unknown condition field '" + field + "'"))
+			return
+		}
+	}
+
+	if err := request.Data.Validate(); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+
+	// The policy judges the stored record the conditions match, which the body
+	// can't swap for another one, or the new record when nothing matches
+	matched, err := models.Find{{.ModelName}}Where(c.DB, request.Conditions)
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
+	if matched == nil {
+		if !c.authorize(ctx, "create", &request.Data) {
+			return
+		}
+	} else {
+		if !c.authorize(ctx, "update", matched) {
+			return
+		}
+		request.Data.ID, request.Data.CreatedAt = matched.ID, matched.CreatedAt
+	}
 
 	if err := request.Data.UpdateOrCreate(c.DB, request.Conditions); err != nil {
-		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
+{{if .Versioned}}	ctx.Header("ETag", request.Data.ETag())
+{{end}}	ctx.JSON(http.StatusOK, gin.H{
 		"data":    request.Data,
 		"message": "{{.ModelName}} upserted successfully",
 	})
 }
 
 // Delete removes a {{.ModelName}} (hard delete)
-// DELETE /{{.TableName}}/:id
+// DELETE /{{.RouteName}}/:id
 func (c *{{.ModelName}}Controller) Delete(ctx *gin.Context) {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	id, err := c.parseID(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("Invalid ID"))
 		return
 	}
 
-	{{.ModelName}}, err := models.Get{{.ModelName}}ByID(c.DB, uint(id))
+	{{.VarName}}, err := models.Get{{.ModelName}}ByID(c.DB, id)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if strings.Contains(err.Error(), "record not found") {
-			status = http.StatusNotFound
-		}
-		ctx.JSON(status, gin.H{"error": err.Error()})
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+
+	if !c.authorize(ctx, "delete", {{.VarName}}) {
+		return
+	}
+{{- if .Versioned}}
+
+	if !c.ifMatch(ctx, {{.VarName}}) {
 		return
 	}
+{{- end}}
 
-	if err := {{.ModelName}}.Delete(c.DB); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := {{.VarName}}.Delete(c.DB); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
 
@@ -191,26 +457,32 @@ func (c *{{.ModelName}}Controller) Delete(ctx *gin.Context) {
 }
 
 // SoftDelete performs soft delete on a {{.ModelName}}
-// DELETE /{{.TableName}}/:id/soft
+// DELETE /{{.RouteName}}/:id/soft
 func (c *{{.ModelName}}Controller) SoftDelete(ctx *gin.Context) {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	id, err := c.parseID(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("Invalid ID"))
 		return
 	}
 
-	{{.ModelName}}, err := models.Get{{.ModelName}}ByID(c.DB, uint(id))
+	{{.VarName}}, err := models.Get{{.ModelName}}ByID(c.DB, id)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if strings.Contains(err.Error(), "record not found") {
-			status = http.StatusNotFound
-		}
-		ctx.JSON(status, gin.H{"error": err.Error()})
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
 
-	if err := {{.ModelName}}.SoftDelete(c.DB); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if !c.authorize(ctx, "delete", {{.VarName}}) {
+		return
+	}
+{{- if .Versioned}}
+
+	if !c.ifMatch(ctx, {{.VarName}}) {
+		return
+	}
+{{- end}}
+
+	if err := {{.VarName}}.SoftDelete(c.DB); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
 
@@ -218,29 +490,42 @@ func (c *{{.ModelName}}Controller) SoftDelete(ctx *gin.Context) {
 }
 
 // Restore restores a soft deleted {{.ModelName}}
-// POST /{{.TableName}}/:id/restore
+// POST /{{.RouteName}}/:id/restore
 func (c *{{.ModelName}}Controller) Restore(ctx *gin.Context) {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	id, err := c.parseID(ctx.Param("id"))
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("Invalid ID"))
+		return
+	}
+
+	{{.VarName}}, err := models.Get{{.ModelName}}ByID(c.DB.Unscoped(), id)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
 
-	{{.ModelName}} := &models.{{.ModelName}}{ID: uint(id)}
-	if err := {{.ModelName}}.Restore(c.DB); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if !c.authorize(ctx, "delete", {{.VarName}}) {
+		return
+	}
+
+	if err := {{.VarName}}.Restore(c.DB); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "{{.ModelName}} restored successfully"})
 }
 
-// Search searches for {{.ModelName}}s
-// GET /{{.TableName}}/search?q=query&page=1&limit=10
+// Search searches for {{.PluralName}}
+// GET /{{.RouteName}}/search?q=query&page=1&limit=10
 func (c *{{.ModelName}}Controller) Search(ctx *gin.Context) {
 	query := ctx.Query("q")
 	if query == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Search query required"})
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("Search query required"))
+		return
+	}
+
+	if !c.authorize(ctx, "view", nil) {
 		return
 	}
 
@@ -248,15 +533,15 @@ func (c *{{.ModelName}}Controller) Search(ctx *gin.Context) {
 	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
 	offset := (page - 1) * limit
 
-	{{.TableName}}, total, err := models.Search{{.ModelName}}s(c.DB, query, limit, offset)
+	{{.PluralVarName}}, total, err := models.Search{{.PluralName}}(c.DB, query, limit, offset, "")
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
 
 	totalPages := (int(total) + limit - 1) / limit
 	ctx.JSON(http.StatusOK, gin.H{
-		"data": {{.TableName}},
+		"data": {{.PluralVarName}},
 		"meta": gin.H{
 			"query":        query,
 			"current_page": page,
@@ -267,26 +552,44 @@ func (c *{{.ModelName}}Controller) Search(ctx *gin.Context) {
 	})
 }
 
-// BatchDelete deletes multiple {{.ModelName}}s
-// DELETE /{{.TableName}}/batch
+// BatchDelete deletes multiple {{.PluralName}}
+// DELETE /{{.RouteName}}/batch
 func (c *{{.ModelName}}Controller) BatchDelete(ctx *gin.Context) {
 	var request struct {
-		IDs  []uint `json:"ids"`
+		IDs  []{{.IDType}} `json:"ids"`
 		Soft bool   `json:"soft"`
 	}
 
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
 		return
 	}
 
 	if len(request.IDs) == 0 {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No IDs provided"})
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("No IDs provided"))
 		return
 	}
 
-	if err := models.BatchDelete{{.ModelName}}s(c.DB, request.IDs, request.Soft); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if c.Policy != nil {
+		var records []models.{{.ModelName}}
+		if err := c.DB.Unscoped().Find(&records, "id IN ?", request.IDs).Error; err != nil {
+			httperr.Write(ctx.Writer, ctx.Request, err)
+			return
+		}
+		var forbidden []string
+		for i := range records {
+			if !c.can(ctx, "delete", &records[i]) {
+				forbidden = append(forbidden, fmt.Sprint(records[i].ID))
+			}
+		}
+		if len(forbidden) > 0 {
+			httperr.Write(ctx.Writer, ctx.Request, httperr.Forbidden("You are not authorized to delete the {{.PluralName}} "+strings.Join(forbidden, ", ")))
+			return
+		}
+	}
+
+	if err := models.BatchDelete{{.PluralName}}(c.DB, request.IDs, request.Soft); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
 
@@ -296,26 +599,424 @@ func (c *{{.ModelName}}Controller) BatchDelete(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "{{.ModelName}}s " + action + " successfully",
+		"message": "{{.PluralName}} " + action + " successfully",
 		"count":   len(request.IDs),
 	})
 }
 
+// BatchStore creates several {{.PluralName}} at once and reports the outcome of
+// every item. Invalid items are skipped and the others inserted in batches;
+// should that fail, they are inserted one at a time to isolate the failures.
+// POST /{{.RouteName}}/batch
+func (c *{{.ModelName}}Controller) BatchStore(ctx *gin.Context) {
+	var request struct {
+		Items []json.RawMessage `json:"items"`
+	}
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+
+	if len(request.Items) == 0 {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("No items provided"))
+		return
+	}
+	if len(request.Items) > c.MaxBatchSize {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("At most "+strconv.Itoa(c.MaxBatchSize)+" items per batch"))
+		return
+	}
+
+	lang := httperr.Language(ctx.Request)
+	results := make([]batch.Result, len(request.Items))
+	var records []models.{{.ModelName}}
+	var positions []int
+	for i, item := range request.Items {
+		var record models.{{.ModelName}}
+		if err := json.Unmarshal(item, &record); err != nil {
+			results[i] = batch.Failed(i, httperr.BadRequest(err.Error()), lang)
+			continue
+		}
+		if err := record.Validate(); err != nil {
+			results[i] = batch.Failed(i, err, lang)
+			continue
+		}
+		if !c.can(ctx, "create", &record) {
+			results[i] = batch.Failed(i, httperr.Forbidden("You are not authorized to create this {{.ModelName}}"), lang)
+			continue
+		}
+		records = append(records, record)
+		positions = append(positions, i)
+	}
+
+	pending := append([]models.{{.ModelName}}(nil), records...)
+	batched := len(records) > 0 && models.Create{{.PluralName}}(c.DB, records, batch.InsertSize) == nil
+	for j, i := range positions {
+		if !batched {
+			// The batch was rolled back: insert the items one at a time to
+			// find out which of them failed
+			records[j] = pending[j]
+			if err := records[j].Create(c.DB); err != nil {
+				results[i] = batch.Failed(i, err, lang)
+				continue
+			}
+		}
+		results[i] = batch.Succeeded(i, http.StatusCreated, records[j])
+	}
+
+	ctx.JSON(batch.Status(results, http.StatusCreated), gin.H{"data": results})
+}
+
+// BatchUpdate applies a merge patch to each of several {{.PluralName}}, every
+// item in its own transaction, and reports the outcome of every item. Items
+// name their record with "id"{{if .Versioned}} and may carry the "version" they were read at{{end}}.
+// PATCH /{{.RouteName}}/batch
+func (c *{{.ModelName}}Controller) BatchUpdate(ctx *gin.Context) {
+	var request struct {
+		Items []json.RawMessage `json:"items"`
+	}
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+
+	if len(request.Items) == 0 {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("No items provided"))
+		return
+	}
+	if len(request.Items) > c.MaxBatchSize {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("At most "+strconv.Itoa(c.MaxBatchSize)+" items per batch"))
+		return
+	}
+
+	lang := httperr.Language(ctx.Request)
+	results := make([]batch.Result, len(request.Items))
+	for i, item := range request.Items {
+		record, err := c.patchItem(ctx, item)
+		if err != nil {
+			results[i] = batch.Failed(i, err, lang)
+			continue
+		}
+		results[i] = batch.Succeeded(i, http.StatusOK, record)
+	}
+
+	ctx.JSON(batch.Status(results, http.StatusOK), gin.H{"data": results})
+}
+
+// patchItem applies a single item of BatchUpdate in a transaction
+func (c *{{.ModelName}}Controller) patchItem(ctx *gin.Context, item json.RawMessage) (*models.{{.ModelName}}, error) {
+	var target struct {
+		ID {{.IDType}} `json:"id"`
+{{- if .Versioned}}
+		Version *uint `json:"version"`
+{{- end}}
+	}
+	if err := json.Unmarshal(item, &target); err != nil {
+		return nil, httperr.BadRequest(err.Error())
+	}
+
+	var patched models.{{.ModelName}}
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		record, err := models.Get{{.ModelName}}ByID(tx, target.ID)
+		if err != nil {
+			return err
+		}
+		if !c.can(ctx, "update", record) {
+			return httperr.Forbidden("You are not authorized to update this {{.ModelName}}")
+		}
+{{- if .Versioned}}
+		if target.Version != nil && *target.Version != record.Version {
+			return httperr.Conflict(models.Err{{.ModelName}}Stale.Error())
+		}
+{{- end}}
+
+		fields, err := patch.Apply(record, &patched, item, "application/merge-patch+json", models.{{.ModelName}}Columns)
+		if err != nil {
+			return httperr.BadRequest(err.Error())
+		}
+		if err := patched.Validate(); err != nil {
+			return err
+		}
+		err = patched.Patch(tx, fields)
+{{- if .Versioned}}
+		if errors.Is(err, models.Err{{.ModelName}}Stale) {
+			err = httperr.Conflict(err.Error())
+		}
+{{- end}}
+		return err
+	})
+	return &patched, err
+}
+
+// Export streams the {{.PluralName}} matching the Index filters as CSV or JSON
+// Lines, one row at a time
+// GET /{{.RouteName}}/export?format=csv&search=query&sort=-created_at&filter[id][in]=1,2
+func (c *{{.ModelName}}Controller) Export(ctx *gin.Context) {
+	if !c.authorize(ctx, "view", nil) {
+		return
+	}
+
+	format, err := transfer.Format(ctx.Query("format"), "")
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+	orderBy, err := query.Sort(ctx.Query("sort"), models.{{.ModelName}}Columns, "created_at DESC")
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+	filter, err := query.Filter(ctx.Request.URL.Query(), models.{{.ModelName}}Columns)
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+
+	out := transfer.NewWriter(ctx.Writer, format, "{{.RouteName}}", models.{{.ModelName}}Fields)
+	records := c.DB.Scopes(filter, models.Match{{.PluralName}}(ctx.Query("search"))).Order(orderBy)
+	err = models.Each{{.PluralName}}(records, func(record *models.{{.ModelName}}) error {
+		return out.Write(record)
+	})
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		if out.Started() {
+			// Part of the file is sent already, all that is left is to cut it short
+			log.Printf("[ERROR] export of {{.RouteName}} aborted: %v", err)
+			return
+		}
+		httperr.Write(ctx.Writer, ctx.Request, err)
+	}
+}
+
+// Import creates {{.PluralName}} from the rows of a CSV or JSON Lines body,
+// inserted in batches as they are read, and reports the lines that failed
+// POST /{{.RouteName}}/import?format=csv|jsonl
+func (c *{{.ModelName}}Controller) Import(ctx *gin.Context) {
+	format, err := transfer.Format(ctx.Query("format"), ctx.GetHeader("Content-Type"))
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+	reader, err := transfer.NewReader(ctx.Request.Body, format, models.{{.ModelName}}{})
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+
+	lang := httperr.Language(ctx.Request)
+	report := &transfer.Report{}
+	var records []models.{{.ModelName}}
+	var lines []int
+	for {
+		lineNum, raw, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			report.Fail(lineNum, httperr.BadRequest(err.Error()), lang)
+			continue
+		}
+
+		var record models.{{.ModelName}}
+		if err := json.Unmarshal(raw, &record); err != nil {
+			report.Fail(lineNum, httperr.BadRequest(err.Error()), lang)
+			continue
+		}
+		if err := record.Validate(); err != nil {
+			report.Fail(lineNum, err, lang)
+			continue
+		}
+		if !c.can(ctx, "create", &record) {
+			report.Fail(lineNum, httperr.Forbidden("You are not authorized to create this {{.ModelName}}"), lang)
+			continue
+		}
+
+		records = append(records, record)
+		lines = append(lines, lineNum)
+		if len(records) == batch.InsertSize {
+			c.importRows(records, lines, report, lang)
+			records, lines = records[:0], lines[:0]
+		}
+	}
+	c.importRows(records, lines, report, lang)
+
+	ctx.JSON(report.Status(), gin.H{"data": report})
+}
+
+// importRows inserts a batch of imported rows, one at a time when the batch
+// fails so that only the failing lines are reported
+func (c *{{.ModelName}}Controller) importRows(records []models.{{.ModelName}}, lines []int, report *transfer.Report, lang string) {
+	if len(records) == 0 {
+		return
+	}
+	pending := append([]models.{{.ModelName}}(nil), records...)
+	if models.Create{{.PluralName}}(c.DB, records, batch.InsertSize) == nil {
+		report.Imported += len(records)
+		return
+	}
+	for i := range pending {
+		if err := pending[i].Create(c.DB); err != nil {
+			report.Fail(lines[i], err, lang)
+			continue
+		}
+		report.Imported++
+	}
+}
+
 // GetByField retrieves a {{.ModelName}} by a specific field
-// GET /{{.TableName}}/by/:field/:value
+// GET /{{.RouteName}}/by/:field/:value
 func (c *{{.ModelName}}Controller) GetByField(ctx *gin.Context) {
 	field := ctx.Param("field")
 	value := ctx.Param("value")
+	if _, ok := models.{{.ModelName}}Columns[field]; !ok {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("Unknown field '" + field + "'"))
+		return
+	}
 
-	{{.ModelName}}, err := models.Get{{.ModelName}}ByField(c.DB, field, value)
+	{{.VarName}}, err := models.Get{{.ModelName}}ByField(c.DB, field, value)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if strings.Contains(err.Error(), "record not found") {
-			status = http.StatusNotFound
-		}
-		ctx.JSON(status, gin.H{"error": err.Error()})
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+
+	if !c.authorize(ctx, "view", {{.VarName}}) {
+		return
+	}
+{{- if .Versioned}}
+
+	ctx.Header("ETag", {{.VarName}}.ETag())
+	if c.matchETag(ctx.GetHeader("If-None-Match"), {{.VarName}}.ETag(), true) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+{{- end}}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": {{.VarName}}})
+}
+
+{{range .HasMany -}}
+// List{{.Name}} returns the {{.Name}} of a {{$.ModelName}} with pagination
+// GET /{{$.RouteName}}/:id/{{.Route}}?page=1&limit=10
+func (c *{{$.ModelName}}Controller) List{{.Name}}(ctx *gin.Context) {
+	id, err := c.parseID(ctx.Param("id"))
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest("Invalid ID"))
+		return
+	}
+
+	{{$.VarName}}, err := models.Get{{$.ModelName}}ByID(c.DB, id)
+	if err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+
+	if !c.authorize(ctx, "view", {{$.VarName}}) {
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	offset := (page - 1) * limit
+
+	association := c.DB.Model({{$.VarName}}).Association("{{.Name}}")
+	total := association.Count()
+	if association.Error != nil {
+		httperr.Write(ctx.Writer, ctx.Request, association.Error)
+		return
+	}
+
+	var {{camel .Name}} []models.{{.Model}}
+	if err := c.DB.Model({{$.VarName}}).Limit(limit).Offset(offset).Order("created_at DESC").Association("{{.Name}}").Find(&{{camel .Name}}); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"data": {{.ModelName}}})
+	totalPages := (int(total) + limit - 1) / limit
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": {{camel .Name}},
+		"meta": gin.H{
+			"current_page": page,
+			"total_pages":  totalPages,
+			"total_count":  total,
+			"limit":        limit,
+		},
+	})
+}
+
+{{end -}}
+// authorize consults the policy for the given action and responds with
+// 403 Forbidden when it is denied. {{.VarName}} is nil for collection endpoints.
+func (c *{{.ModelName}}Controller) authorize(ctx *gin.Context, action string, {{.VarName}} *models.{{.ModelName}}) bool {
+	if !c.can(ctx, action, {{.VarName}}) {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.Forbidden("You are not authorized to "+action+" this {{.ModelName}}"))
+		return false
+	}
+	return true
+}
+
+// can reports whether the policy allows the given action
+func (c *{{.ModelName}}Controller) can(ctx *gin.Context, action string, {{.VarName}} *models.{{.ModelName}}) bool {
+	if c.Policy == nil {
+		return true
+	}
+
+	user, _ := ctx.Get("user")
+
+	var allowed bool
+	switch action {
+	case "view":
+		allowed = c.Policy.CanView(user, {{.VarName}})
+	case "create":
+		allowed = c.Policy.CanCreate(user, {{.VarName}})
+	case "update":
+		allowed = c.Policy.CanUpdate(user, {{.VarName}})
+	case "delete":
+		allowed = c.Policy.CanDelete(user, {{.VarName}})
+	}
+	return allowed
+}
+
+{{if .Versioned -}}
+// ifMatch responds with 412 Precondition Failed when the request's If-Match
+// header names another version of {{.VarName}}
+func (c *{{.ModelName}}Controller) ifMatch(ctx *gin.Context, {{.VarName}} *models.{{.ModelName}}) bool {
+	header := ctx.GetHeader("If-Match")
+	if header == "" || c.matchETag(header, {{.VarName}}.ETag(), false) {
+		return true
+	}
+	httperr.Write(ctx.Writer, ctx.Request, httperr.PreconditionFailed("The {{.ModelName}} was modified since it was read"))
+	return false
+}
+
+// matchETag reports whether an If-Match or If-None-Match header lists etag
+// or *; weak comparison, used by If-None-Match, ignores W/ prefixes
+func (c *{{.ModelName}}Controller) matchETag(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+{{end -}}
+// parseID parses the {{.ModelName}} id in a request path
+func (c *{{.ModelName}}Controller) parseID(raw string) ({{.IDType}}, error) {
+{{- if eq .PrimaryKey "uuid"}}
+	id, err := uuid.Parse(raw)
+	return id.String(), err
+{{- else if eq .PrimaryKey "ulid"}}
+	id, err := ulid.ParseStrict(raw)
+	return id.String(), err
+{{- else}}
+	id, err := strconv.ParseUint(raw, 10, 32)
+	return uint(id), err
+{{- end}}
 }
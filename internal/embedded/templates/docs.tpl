@@ -0,0 +1,65 @@
+package docs
+
+import (
+	_ "embed"
+	"net/http"
+	"strings"
+)
+
+// Spec is the OpenAPI document generated by `went openapi:generate`
+//
+//go:embed openapi.yaml
+var Spec []byte
+
+const swaggerUI = `<!DOCTYPE html>
+<html>
+<head>
+  <title>{{.ProjectName}} API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({ url: "SPEC_URL", dom_id: "#swagger-ui" });</script>
+</body>
+</html>`
+
+const redoc = `<!DOCTYPE html>
+<html>
+<head>
+  <title>{{.ProjectName}} API</title>
+</head>
+<body>
+  <redoc spec-url="SPEC_URL"></redoc>
+  <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>`
+
+// Handler serves the API documentation. Mount it under /docs:
+//
+//	Gin: router.Any("/docs/*any", gin.WrapH(docs.Handler()))
+//	Chi: router.Mount("/docs", docs.Handler())
+//
+// GET /docs serves Swagger UI, /docs/redoc serves Redoc and
+// /docs/openapi.yaml serves the raw specification.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimSuffix(r.URL.Path, "/")
+
+		switch {
+		case strings.HasSuffix(path, "/openapi.yaml"):
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write(Spec)
+		case strings.HasSuffix(path, "/redoc"):
+			page(w, redoc, strings.TrimSuffix(path, "/redoc")+"/openapi.yaml")
+		default:
+			page(w, swaggerUI, path+"/openapi.yaml")
+		}
+	})
+}
+
+// page writes an HTML documentation page pointing at the spec URL
+func page(w http.ResponseWriter, html, specURL string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(strings.Replace(html, "SPEC_URL", specURL, 1)))
+}
@@ -0,0 +1,167 @@
+// Package httperr writes errors as RFC 7807 application/problem+json
+// responses. Domain errors map to their status codes; anything else is
+// logged with the trace id and answered with a generic 500, so database
+// internals never reach clients.
+package httperr
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// Domain errors, matched with errors.Is; wrap them to add a client-facing detail
+var (
+	ErrBadRequest = errors.New("bad request")
+	ErrForbidden  = errors.New("forbidden")
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+
+	// ErrPreconditionFailed answers If-Match headers naming a stale version
+	ErrPreconditionFailed = errors.New("precondition failed")
+
+	// ErrNotImplemented marks generated handlers that still need a body
+	ErrNotImplemented = errors.New("not implemented")
+)
+
+// Problem is an RFC 7807 problem details body
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	TraceID  string              `json:"trace_id"`
+	Errors   map[string][]string `json:"errors,omitempty"` // validation messages by JSON field
+}
+
+// Error is a domain error with a detail safe to show to clients
+type Error struct {
+	Kind   error  // one of the Err* values
+	Detail string // shown to the client
+}
+
+func (e *Error) Error() string { return e.Detail }
+
+func (e *Error) Unwrap() error { return e.Kind }
+
+// BadRequest returns a 400 error with the given detail
+func BadRequest(detail string) error { return &Error{Kind: ErrBadRequest, Detail: detail} }
+
+// Forbidden returns a 403 error with the given detail
+func Forbidden(detail string) error { return &Error{Kind: ErrForbidden, Detail: detail} }
+
+// NotFound returns a 404 error with the given detail
+func NotFound(detail string) error { return &Error{Kind: ErrNotFound, Detail: detail} }
+
+// Conflict returns a 409 error with the given detail
+func Conflict(detail string) error { return &Error{Kind: ErrConflict, Detail: detail} }
+
+// PreconditionFailed returns a 412 error with the given detail
+func PreconditionFailed(detail string) error {
+	return &Error{Kind: ErrPreconditionFailed, Detail: detail}
+}
+
+// NotImplemented returns a 501 error with the given detail
+func NotImplemented(detail string) error { return &Error{Kind: ErrNotImplemented, Detail: detail} }
+
+// Write answers r with the problem err maps to, with validation messages in
+// the language r accepts
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	problem := FromError(err, Language(r))
+	problem.Instance = r.URL.Path
+	problem.TraceID = TraceID(r)
+	if problem.Status == http.StatusInternalServerError {
+		log.Printf("[ERROR] %s %s (trace %s): %v", r.Method, r.URL.Path, problem.TraceID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// FromError maps err to a problem without request details
+func FromError(err error, lang string) *Problem {
+	var validationErrors validator.ValidationErrors
+	var domain *Error
+	switch {
+	case errors.As(err, &validationErrors):
+		problem := newProblem(http.StatusUnprocessableEntity, "The request contains invalid fields")
+		problem.Errors = ValidationMessages(validationErrors, lang)
+		return problem
+	case errors.As(err, &domain):
+		return newProblem(status(domain.Kind), domain.Detail)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return newProblem(http.StatusNotFound, "The requested record does not exist")
+	case errors.Is(err, gorm.ErrDuplicatedKey), isDuplicate(err):
+		return newProblem(http.StatusConflict, "A record with the same unique values already exists")
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return newProblem(http.StatusConflict, "The record references or is referenced by other records")
+	}
+	for _, kind := range []error{ErrBadRequest, ErrForbidden, ErrNotFound, ErrConflict, ErrValidation, ErrPreconditionFailed, ErrNotImplemented} {
+		if errors.Is(err, kind) {
+			return newProblem(status(kind), kind.Error())
+		}
+	}
+	return newProblem(http.StatusInternalServerError, "")
+}
+
+// TraceID returns the trace id of r: that of a W3C traceparent header, an
+// X-Request-ID header, or a new random id
+func TraceID(r *http.Request) string {
+	if parts := strings.Split(r.Header.Get("traceparent"), "-"); len(parts) == 4 && len(parts[1]) == 32 {
+		return parts[1]
+	}
+	if id := r.Header.Get("X-Request-ID"); id != "" {
+		return id
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// newProblem returns a problem with the standard title of status
+func newProblem(status int, detail string) *Problem {
+	return &Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: detail}
+}
+
+// status returns the status code of a domain error
+func status(kind error) int {
+	switch kind {
+	case ErrBadRequest:
+		return http.StatusBadRequest
+	case ErrForbidden:
+		return http.StatusForbidden
+	case ErrNotFound:
+		return http.StatusNotFound
+	case ErrConflict:
+		return http.StatusConflict
+	case ErrValidation:
+		return http.StatusUnprocessableEntity
+	case ErrPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case ErrNotImplemented:
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
+
+// isDuplicate recognizes unique violations of drivers whose errors GORM
+// doesn't translate (TranslateError is off by default)
+func isDuplicate(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	return strings.Contains(message, "duplicate key") ||
+		strings.Contains(message, "UNIQUE constraint failed") ||
+		strings.Contains(message, "Duplicate entry")
+}
@@ -0,0 +1,124 @@
+package httperr
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// DefaultLanguage is used when Accept-Language names no supported language
+const DefaultLanguage = "en"
+
+// messages holds the validation messages of each supported language, keyed
+// by rule. %s is replaced with the rule parameter; rules comparing lengths
+// have a "<rule>:len" variant for strings, slices and maps.
+var messages = map[string]map[string]string{
+	"en": {
+		"required":    "is required",
+		"required_if": "is required",
+		"email":       "must be a valid email address",
+		"url":         "must be a valid URL",
+		"uuid":        "must be a valid UUID",
+		"numeric":     "must be numeric",
+		"alpha":       "must contain letters only",
+		"alphanum":    "must contain letters and digits only",
+		"oneof":       "must be one of: %s",
+		"eqfield":     "must match %s",
+		"min":         "must be at least %s",
+		"min:len":     "must be at least %s characters long",
+		"max":         "must be at most %s",
+		"max:len":     "must be at most %s characters long",
+		"len":         "must be %s",
+		"len:len":     "must be %s characters long",
+		"gt":          "must be greater than %s",
+		"gt:len":      "must be longer than %s characters",
+		"gte":         "must be at least %s",
+		"gte:len":     "must be at least %s characters long",
+		"lt":          "must be less than %s",
+		"lt:len":      "must be shorter than %s characters",
+		"lte":         "must be at most %s",
+		"lte:len":     "must be at most %s characters long",
+		"invalid":     "is invalid",
+	},
+	"tr": {
+		"required":    "zorunludur",
+		"required_if": "zorunludur",
+		"email":       "geçerli bir e-posta adresi olmalıdır",
+		"url":         "geçerli bir URL olmalıdır",
+		"uuid":        "geçerli bir UUID olmalıdır",
+		"numeric":     "sayısal olmalıdır",
+		"alpha":       "yalnızca harf içermelidir",
+		"alphanum":    "yalnızca harf ve rakam içermelidir",
+		"oneof":       "şunlardan biri olmalıdır: %s",
+		"eqfield":     "%s ile aynı olmalıdır",
+		"min":         "en az %s olmalıdır",
+		"min:len":     "en az %s karakter olmalıdır",
+		"max":         "en fazla %s olmalıdır",
+		"max:len":     "en fazla %s karakter olmalıdır",
+		"len":         "%s olmalıdır",
+		"len:len":     "%s karakter olmalıdır",
+		"gt":          "%s değerinden büyük olmalıdır",
+		"gt:len":      "%s karakterden uzun olmalıdır",
+		"gte":         "en az %s olmalıdır",
+		"gte:len":     "en az %s karakter olmalıdır",
+		"lt":          "%s değerinden küçük olmalıdır",
+		"lt:len":      "%s karakterden kısa olmalıdır",
+		"lte":         "en fazla %s olmalıdır",
+		"lte:len":     "en fazla %s karakter olmalıdır",
+		"invalid":     "geçersizdir",
+	},
+}
+
+// ValidationMessages turns validation errors into messages keyed by the JSON
+// path of each invalid field ("name", "items[0].price"), in lang
+func ValidationMessages(errs validator.ValidationErrors, lang string) map[string][]string {
+	catalog, ok := messages[lang]
+	if !ok {
+		catalog = messages[DefaultLanguage]
+	}
+
+	fields := map[string][]string{}
+	for _, fe := range errs {
+		field := fe.Namespace()
+		if _, rest, ok := strings.Cut(field, "."); ok {
+			field = rest // drop the struct name
+		}
+		fields[field] = append(fields[field], message(catalog, fe))
+	}
+	return fields
+}
+
+// message returns the message of a single failed rule
+func message(catalog map[string]string, fe validator.FieldError) string {
+	rule := fe.Tag()
+	switch fe.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if _, ok := catalog[rule+":len"]; ok {
+			rule += ":len"
+		}
+	}
+	format, ok := catalog[rule]
+	if !ok {
+		return catalog["invalid"]
+	}
+	if strings.Contains(format, "%s") {
+		return fmt.Sprintf(format, strings.ReplaceAll(fe.Param(), " ", ", "))
+	}
+	return format
+}
+
+// Language returns the first language of r's Accept-Language header that has
+// messages, or DefaultLanguage
+func Language(r *http.Request) string {
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if _, ok := messages[base]; ok {
+			return base
+		}
+	}
+	return DefaultLanguage
+}
@@ -0,0 +1,208 @@
+package query
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidCursor is returned for cursors that weren't issued by Find
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Keyset paginates a list by the sort column and id of the records at the
+// edges of a page instead of an offset, so deep pages cost as much as the
+// first one and no COUNT is needed
+type Keyset struct {
+	Column string  // sort column
+	Desc   bool    // descending order
+	cursor *cursor // position to continue from, nil for the first page
+}
+
+// cursor is the decoded form of the opaque next_cursor and prev_cursor values
+type cursor struct {
+	Column string      `json:"c"`
+	Desc   bool        `json:"d,omitempty"`
+	Key    interface{} `json:"k"`
+	ID     interface{} `json:"i"`
+	Before bool        `json:"b,omitempty"` // the page ends before the position instead of starting after it
+}
+
+// NewKeyset parses the sort and cursor parameters of a list request. sort
+// names a single field of columns, optionally prefixed with - for descending
+// order, and defaults to fallback; cursor is the next_cursor or prev_cursor
+// of a previous page, empty for the first one.
+func NewKeyset(sort, cursor string, columns map[string]string, fallback string) (*Keyset, error) {
+	if strings.TrimSpace(sort) == "" {
+		sort = fallback
+	}
+	if strings.Contains(sort, ",") {
+		return nil, fmt.Errorf("cursor pagination sorts by a single field, got '%s'", sort)
+	}
+	field, desc := strings.TrimSpace(sort), false
+	if strings.HasPrefix(field, "-") {
+		field, desc = field[1:], true
+	}
+	column, ok := columns[field]
+	if !ok {
+		return nil, fmt.Errorf("cannot sort by unknown field '%s'", field)
+	}
+
+	k := &Keyset{Column: column, Desc: desc}
+	if cursor == "" {
+		return k, nil
+	}
+	c, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if c.Column != k.Column || c.Desc != k.Desc {
+		return nil, fmt.Errorf("%w: issued for another sort order", ErrInvalidCursor)
+	}
+	k.cursor = c
+	return k, nil
+}
+
+// Find loads up to limit records of db's model into dest, a pointer to a
+// slice, and returns the cursors of the next and previous pages, empty when
+// there is none
+func (k *Keyset) Find(db *gorm.DB, dest interface{}, limit int) (next, prev string, err error) {
+	if err := db.Statement.Parse(db.Statement.Model); err != nil {
+		return "", "", err
+	}
+	sortField := db.Statement.Schema.LookUpField(k.Column)
+	idField := db.Statement.Schema.LookUpField("id")
+	if sortField == nil || idField == nil {
+		return "", "", fmt.Errorf("%s has no %s or id column", db.Statement.Schema.Name, k.Column)
+	}
+
+	backward := k.cursor != nil && k.cursor.Before
+	desc := k.Desc != backward
+	tx := db
+	if k.cursor != nil {
+		key, err := typedValue(sortField, k.cursor.Key)
+		if err != nil {
+			return "", "", err
+		}
+		id, err := typedValue(idField, k.cursor.ID)
+		if err != nil {
+			return "", "", err
+		}
+		tx = tx.Where(clause.Or(
+			beyond(k.Column, key, desc),
+			clause.And(clause.Eq{Column: clause.Column{Name: k.Column}, Value: key}, beyond("id", id, desc)),
+		))
+	}
+	err = tx.
+		Order(clause.OrderByColumn{Column: clause.Column{Name: k.Column}, Desc: desc}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: desc}).
+		Limit(limit + 1).
+		Find(dest).Error
+	if err != nil {
+		return "", "", err
+	}
+
+	records := reflect.ValueOf(dest).Elem()
+	more := records.Len() > limit
+	if more {
+		records.Set(records.Slice(0, limit))
+	}
+	if backward {
+		swap := reflect.Swapper(records.Interface())
+		for i, j := 0, records.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+	if records.Len() == 0 {
+		return "", "", nil
+	}
+
+	// Going forward there is a next page when the query found more records and
+	// a previous one after any cursor; going backward the other way round
+	hasNext, hasPrev := more, k.cursor != nil
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+	ctx := tx.Statement.Context
+	if hasNext {
+		if next, err = k.encode(ctx, sortField, idField, records.Index(records.Len()-1), false); err != nil {
+			return "", "", err
+		}
+	}
+	if hasPrev {
+		if prev, err = k.encode(ctx, sortField, idField, records.Index(0), true); err != nil {
+			return "", "", err
+		}
+	}
+	return next, prev, nil
+}
+
+// encode returns the cursor of the position of record
+func (k *Keyset) encode(ctx context.Context, sortField, idField *schema.Field, record reflect.Value, before bool) (string, error) {
+	c := cursor{Column: k.Column, Desc: k.Desc, Before: before}
+	c.Key, _ = sortField.ValueOf(ctx, record)
+	c.ID, _ = idField.ValueOf(ctx, record)
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor parses an opaque cursor
+func decodeCursor(value string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	var c cursor
+	if err := decoder.Decode(&c); err != nil || c.Column == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// beyond returns the condition matching the values of column that come after
+// value in the given order
+func beyond(column string, value interface{}, desc bool) clause.Expression {
+	if desc {
+		return clause.Lt{Column: clause.Column{Name: column}, Value: value}
+	}
+	return clause.Gt{Column: clause.Column{Name: column}, Value: value}
+}
+
+// typedValue converts a value decoded from a cursor back to the type of
+// field, so that times and large integers compare correctly
+func typedValue(field *schema.Field, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, ErrInvalidCursor
+	}
+	kind := field.IndirectFieldType.Kind()
+	text := fmt.Sprint(value)
+	var err error
+	switch {
+	case field.IndirectFieldType == reflect.TypeOf(time.Time{}):
+		value, err = time.Parse(time.RFC3339Nano, text)
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		value, err = strconv.ParseInt(text, 10, 64)
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		value, err = strconv.ParseUint(text, 10, 64)
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		value, err = strconv.ParseFloat(text, 64)
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return value, nil
+}
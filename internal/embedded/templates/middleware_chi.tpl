@@ -0,0 +1,48 @@
+package {{or .Package "middleware"}}
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// {{.ModelName}}Middleware implements {{.ModelName}} middleware
+func {{.ModelName}}Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// TODO: Implement your middleware logic here
+		// Example: Authentication, logging, rate limiting, etc.
+
+		// For example, a simple authentication check:
+		// token := r.Header.Get("Authorization")
+		// if token == "" {
+		//     http.Error(w, `{"error":"Authorization header required"}`, http.StatusUnauthorized)
+		//     return
+		// }
+
+		// Continue to next handler
+		next.ServeHTTP(w, r)
+	})
+}
+
+// {{.ModelName}}Logger logs requests for {{.ModelName}} endpoints
+func {{.ModelName}}Logger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+		next.ServeHTTP(ww, r)
+
+		// Custom log format for {{.ModelName}} endpoints
+		log.Printf("%s - [%s] \"%s %s %s %d %s \"%s\"\"",
+			r.RemoteAddr,
+			start.Format("02/Jan/2006:15:04:05 -0700"),
+			r.Method,
+			r.URL.Path,
+			r.Proto,
+			ww.Status(),
+			time.Since(start),
+			r.UserAgent(),
+		)
+	})
+}
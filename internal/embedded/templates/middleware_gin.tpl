@@ -1,4 +1,4 @@
-package middleware
+package {{or .Package "middleware"}}
 
 import (
 	"fmt"
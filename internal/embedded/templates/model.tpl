@@ -1,23 +1,47 @@
-package models
+package {{or .Package "models"}}
 
 import (
-	"time"
-	"gorm.io/gorm"
-	"github.com/go-playground/validator/v10"
-)
+	"errors"
+	"fmt"
+	{{if or .Relations .StringFields}}"strings"
+	{{end}}"time"
 
-var validate = validator.New()
+	{{if eq .PrimaryKey "uuid"}}"github.com/google/uuid"
+	{{else if eq .PrimaryKey "ulid"}}"github.com/oklog/ulid/v2"
+	{{end}}"gorm.io/gorm"
+	"gorm.io/gorm/clause"{{if eq .Pagination "cursor"}}
+	"{{.ModulePath}}/app/query"{{end}}
+)
 
 // {{.ModelName}} represents the {{.ModelName}} model
 type {{.ModelName}} struct {
+{{- if eq .PrimaryKey "uuid"}}
+	ID          string         `json:"id" gorm:"primaryKey;{{if eq .Config.Database "postgres"}}type:uuid{{else}}size:36{{end}}"`
+{{- else if eq .PrimaryKey "ulid"}}
+	ID          string         `json:"id" gorm:"primaryKey;size:26"`
+{{- else}}
 	ID          uint           `json:"id" gorm:"primaryKey,autoIncrement"`
-	Name        string         `json:"name" gorm:"column:name" validate:"required_if=ID 0"`
-	Description string         `json:"description" gorm:"column:description" validate:"omitempty,max=255"`
+{{- end}}
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+{{- range .Relations}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+{{- if .Versioned}}
+	Version     uint           `json:"version" gorm:"not null;default:1"`
+{{- end}}
 	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
 
+{{if .Versioned -}}
+// Err{{.ModelName}}Stale is returned by updates of a {{.ModelName}} that someone
+// else changed since it was read
+var Err{{.ModelName}}Stale = errors.New("the {{.VarName}} was modified since it was read")
+
+{{end -}}
 // TableName returns the table name for {{.ModelName}}
 func ({{.ModelName}}) TableName() string {
 	return "{{.TableName}}"
@@ -30,6 +54,18 @@ func (m *{{.ModelName}}) Validate() error {
 
 // BeforeCreate hook
 func (m *{{.ModelName}}) BeforeCreate(tx *gorm.DB) error {
+{{- if eq .PrimaryKey "uuid"}}
+	if m.ID == "" {
+		m.ID = uuid.NewString()
+	}
+{{- else if eq .PrimaryKey "ulid"}}
+	if m.ID == "" {
+		m.ID = ulid.Make().String()
+	}
+{{- end}}
+{{- if .Versioned}}
+	m.Version = 1
+{{- end}}
 	return m.Validate()
 }
 
@@ -38,14 +74,70 @@ func (m *{{.ModelName}}) BeforeUpdate(tx *gorm.DB) error {
 	return m.Validate()
 }
 
-// Create creates a new {{.ModelName}}
+// Create creates a new {{.ModelName}}. Associations are never written along:
+// they change through their own models, under their own policies.
 func (m *{{.ModelName}}) Create(db *gorm.DB) error {
-	return db.Create(m).Error
+	return db.Omit(clause.Associations).Create(m).Error
+}
+
+// Create{{.PluralName}} inserts {{.PluralVarName}} in a single transaction,
+// batchSize rows per statement, without their associations
+func Create{{.PluralName}}(db *gorm.DB, {{.PluralVarName}} []{{.ModelName}}, batchSize int) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		return tx.Omit(clause.Associations).CreateInBatches({{.PluralVarName}}, batchSize).Error
+	})
+}
+
+{{- if .Relations}}
+// {{.ModelName}}Includes maps the names accepted by ?include= to the
+// associations they preload
+var {{.ModelName}}Includes = map[string]string{
+{{- range .Relations}}
+	"{{.Include}}": "{{.Name}}",
+{{- end}}
+}
+
+// Preload{{.ModelName}}Includes returns a scope preloading the comma separated
+// associations in include, e.g. "{{(index .Relations 0).Include}}"; unknown names are rejected
+func Preload{{.ModelName}}Includes(include string) (func(*gorm.DB) *gorm.DB, error) {
+	var associations []string
+	for _, name := range strings.Split(include, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		association, ok := {{.ModelName}}Includes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown include '%s'", name)
+		}
+		associations = append(associations, association)
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		for _, association := range associations {
+			db = db.Preload(association)
+		}
+		return db
+	}, nil
 }
+{{end}}
+// {{.ModelName}}Columns maps the fields accepted by sort, filter and
+// by-field lookups to their columns
+var {{.ModelName}}Columns = map[string]string{
+	"id": "id",
+{{- range .Fields}}{{if .JSONName}}
+	"{{.JSONName}}": "{{.Column}}",
+{{- end}}{{end}}
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// {{.ModelName}}Fields lists the fields of {{.ModelName}}Columns in declaration
+// order, the columns of CSV exports
+var {{.ModelName}}Fields = []string{"id"{{range .Fields}}{{if .JSONName}}, "{{.JSONName}}"{{end}}{{end}}, "created_at", "updated_at"}
 
-// GetAll{{.ModelName}}s retrieves all {{.ModelName}}s with pagination
-func GetAll{{.ModelName}}s(db *gorm.DB, limit, offset int, orderBy string) ([]{{.ModelName}}, int64, error) {
-	var {{.TableName}} []{{.ModelName}}
+// GetAll{{.PluralName}} retrieves all {{.PluralName}} with pagination; scopes
+// apply to the page query only, e.g. to preload associations
+func GetAll{{.PluralName}}(db *gorm.DB, limit, offset int, orderBy string, scopes ...func(*gorm.DB) *gorm.DB) ([]{{.ModelName}}, int64, error) {
+	var {{.PluralVarName}} []{{.ModelName}}
 	var count int64
 
 	if orderBy == "" {
@@ -62,41 +154,165 @@ func GetAll{{.ModelName}}s(db *gorm.DB, limit, offset int, orderBy string) ([]{{
 		query = query.Offset(offset)
 	}
 
-	err := query.Order(orderBy).Find(&{{.TableName}}).Error
-	return {{.TableName}}, count, err
+	err := query.Scopes(scopes...).Order(orderBy).Find(&{{.PluralVarName}}).Error
+	return {{.PluralVarName}}, count, err
 }
 
 // Get{{.ModelName}}ByID retrieves a {{.ModelName}} by ID
-func Get{{.ModelName}}ByID(db *gorm.DB, id uint) (*{{.ModelName}}, error) {
-	var {{.ModelName}} {{.ModelName}}
-	err := db.First(&{{.ModelName}}, id).Error
-	return &{{.ModelName}}, err
+func Get{{.ModelName}}ByID(db *gorm.DB, id {{.IDType}}, scopes ...func(*gorm.DB) *gorm.DB) (*{{.ModelName}}, error) {
+	var {{.VarName}} {{.ModelName}}
+	err := db.Scopes(scopes...).First(&{{.VarName}}, "id = ?", id).Error
+	return &{{.VarName}}, err
 }
 
-// Get{{.ModelName}}ByField retrieves a {{.ModelName}} by any field
+// Get{{.ModelName}}ByField retrieves a {{.ModelName}} by a field of {{.ModelName}}Columns
 func Get{{.ModelName}}ByField(db *gorm.DB, field string, value interface{}) (*{{.ModelName}}, error) {
-	var {{.ModelName}} {{.ModelName}}
-	err := db.Where(field+" = ?", value).First(&{{.ModelName}}).Error
-	return &{{.ModelName}}, err
+	column, ok := {{.ModelName}}Columns[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown field '%s'", field)
+	}
+	var {{.VarName}} {{.ModelName}}
+	err := db.Where(clause.Eq{Column: clause.Column{Name: column}, Value: value}).First(&{{.VarName}}).Error
+	return &{{.VarName}}, err
 }
 
-// Update updates the {{.ModelName}}
+// Find{{.ModelName}}Where returns the first {{.ModelName}} matching conditions,
+// keyed by the fields of {{.ModelName}}Columns, or nil when none does
+func Find{{.ModelName}}Where(db *gorm.DB, conditions map[string]interface{}) (*{{.ModelName}}, error) {
+	query, err := where{{.ModelName}}(db, conditions)
+	if err != nil {
+		return nil, err
+	}
+	var {{.VarName}} {{.ModelName}}
+	err = query.First(&{{.VarName}}).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &{{.VarName}}, nil
+}
+
+// where{{.ModelName}} restricts db to the records matching conditions, keyed by
+// the fields of {{.ModelName}}Columns
+func where{{.ModelName}}(db *gorm.DB, conditions map[string]interface{}) (*gorm.DB, error) {
+	for field, value := range conditions {
+		column, ok := {{.ModelName}}Columns[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown field '%s'", field)
+		}
+		db = db.Where(clause.Eq{Column: clause.Column{Name: column}, Value: value})
+	}
+	return db, nil
+}
+
+{{- if .Versioned}}
+// Update saves the {{.ModelName}}, without its associations, and increments
+// its version, provided its record is still at the version it was read with
 func (m *{{.ModelName}}) Update(db *gorm.DB) error {
-	return db.Save(m).Error
+	version := m.Version
+	m.Version++
+	result := db.Model(m).Where(clause.Eq{Column: clause.Column{Name: "version"}, Value: version}).Select("*").Omit(clause.Associations).Updates(m)
+	return m.checkVersion(result, version)
+}
+
+// UpdateFields updates specific fields of the {{.ModelName}} and increments
+// its version, provided its record is still at the version it was read with
+func (m *{{.ModelName}}) UpdateFields(db *gorm.DB, fields map[string]interface{}) error {
+	updates := make(map[string]interface{}, len(fields)+1)
+	for column, value := range fields {
+		updates[column] = value
+	}
+	version := m.Version
+	m.Version++
+	updates["version"] = m.Version
+	result := db.Model(m).Where(clause.Eq{Column: clause.Column{Name: "version"}, Value: version}).Updates(updates)
+	return m.checkVersion(result, version)
+}
+
+// checkVersion turns an update that matched no record into
+// Err{{.ModelName}}Stale, restoring the version the {{.ModelName}} was read with
+func (m *{{.ModelName}}) checkVersion(result *gorm.DB, version uint) error {
+	switch {
+	case result.Error != nil:
+		m.Version = version
+		return result.Error
+	case result.RowsAffected == 0:
+		m.Version = version
+		return Err{{.ModelName}}Stale
+	}
+	return nil
+}
+
+// ETag returns the entity tag of the {{.ModelName}}'s current version
+func (m *{{.ModelName}}) ETag() string {
+	return fmt.Sprintf(`"%d"`, m.Version)
+}
+{{- else}}
+// Update updates the {{.ModelName}}, without its associations
+func (m *{{.ModelName}}) Update(db *gorm.DB) error {
+	return db.Omit(clause.Associations).Save(m).Error
 }
 
 // UpdateFields updates specific fields of the {{.ModelName}}
 func (m *{{.ModelName}}) UpdateFields(db *gorm.DB, fields map[string]interface{}) error {
 	return db.Model(m).Updates(fields).Error
 }
+{{- end}}
+
+// Patch writes the given fields of the {{.ModelName}}, keyed by JSON name, to
+// its record, zero values included
+func (m *{{.ModelName}}) Patch(db *gorm.DB, fields []string) error {
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column, ok := {{.ModelName}}Columns[field]
+		if !ok {
+			return fmt.Errorf("unknown field '%s'", field)
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+{{- if .Versioned}}
+	version := m.Version
+	m.Version++
+	result := db.Model(m).Where(clause.Eq{Column: clause.Column{Name: "version"}, Value: version}).Select(append(columns, "version")).Updates(m)
+	return m.checkVersion(result, version)
+{{- else}}
+	return db.Model(m).Select(columns).Updates(m).Error
+{{- end}}
+}
 
-// UpdateOrCreate updates an existing {{.ModelName}} or creates a new one
+// UpdateOrCreate updates an existing {{.ModelName}} or creates a new one,
+// without its associations; conditions are keyed by the fields of
+// {{.ModelName}}Columns
 func (m *{{.ModelName}}) UpdateOrCreate(db *gorm.DB, conditions map[string]interface{}) error {
-	query := db
-	for field, value := range conditions {
-		query = query.Where(field+" = ?", value)
+	query, err := where{{.ModelName}}(db.Omit(clause.Associations), conditions)
+	if err != nil {
+		return err
+	}
+{{- if .Versioned}}
+
+	// A match is updated under the version check and then reloaded
+	var existing {{.ModelName}}
+	err = query.Session(&gorm.Session{}).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return query.Assign(m).FirstOrCreate(m).Error
+	}
+	if err != nil {
+		return err
 	}
+	m.ID, m.Version = existing.ID, existing.Version+1
+	result := db.Model(m).Where(clause.Eq{Column: clause.Column{Name: "version"}, Value: existing.Version}).Omit(clause.Associations).Updates(m)
+	if err := m.checkVersion(result, existing.Version); err != nil {
+		return err
+	}
+	return db.First(m).Error
+{{- else}}
 	return query.Assign(m).FirstOrCreate(m).Error
+{{- end}}
 }
 
 // Delete performs hard delete
@@ -111,15 +327,24 @@ func (m *{{.ModelName}}) SoftDelete(db *gorm.DB) error {
 
 // Restore restores a soft deleted {{.ModelName}}
 func (m *{{.ModelName}}) Restore(db *gorm.DB) error {
+{{- if .Versioned}}
+	return db.Unscoped().Model(m).Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error
+{{- else}}
 	return db.Unscoped().Model(m).Update("deleted_at", nil).Error
+{{- end}}
 }
 
-// Search{{.ModelName}}s searches {{.ModelName}}s by name or description
-func Search{{.ModelName}}s(db *gorm.DB, query string, limit, offset int) ([]{{.ModelName}}, int64, error) {
-	var {{.TableName}} []{{.ModelName}}
+// Search{{.PluralName}} searches {{.PluralName}} by their text fields, newest
+// first unless orderBy is given; scopes apply to the page query only
+func Search{{.PluralName}}(db *gorm.DB, query string, limit, offset int, orderBy string, scopes ...func(*gorm.DB) *gorm.DB) ([]{{.ModelName}}, int64, error) {
+	var {{.PluralVarName}} []{{.ModelName}}
 	var count int64
 
-	searchQuery := db.Model(&{{.ModelName}}{}).Where("name ILIKE ? OR description ILIKE ?", "%"+query+"%", "%"+query+"%")
+	if orderBy == "" {
+		orderBy = "created_at DESC"
+	}
+
+	searchQuery := db.Model(&{{.ModelName}}{}).Scopes(Match{{.PluralName}}(query))
 	searchQuery.Count(&count)
 
 	if limit > 0 {
@@ -129,24 +354,84 @@ func Search{{.ModelName}}s(db *gorm.DB, query string, limit, offset int) ([]{{.M
 		searchQuery = searchQuery.Offset(offset)
 	}
 
-	err := searchQuery.Order("created_at DESC").Find(&{{.TableName}}).Error
-	return {{.TableName}}, count, err
+	err := searchQuery.Scopes(scopes...).Order(orderBy).Find(&{{.PluralVarName}}).Error
+	return {{.PluralVarName}}, count, err
+}
+
+// Match{{.PluralName}} returns a scope limiting a query to the {{.PluralName}}
+// whose text fields contain query, % and _ included; an empty query matches
+// every record
+func Match{{.PluralName}}(query string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query == "" {
+			return db
+		}
+{{- with .StringFields}}
+		pattern := "%" + strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(query) + "%"
+		return db.Where("{{range $i, $f := .}}{{if $i}} OR {{end}}{{$f.Column}} {{if eq $.Config.Database "postgres"}}ILIKE{{else}}LIKE{{end}} ? ESCAPE '!'{{end}}"{{range .}}, pattern{{end}})
+{{- else}}
+		return db
+{{- end}}
+	}
+}
+
+// Each{{.PluralName}} calls fn with every {{.ModelName}} matched by db, one row at
+// a time, so that any number of records can be streamed
+func Each{{.PluralName}}(db *gorm.DB, fn func(*{{.ModelName}}) error) error {
+	rows, err := db.Model(&{{.ModelName}}{}).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var {{.VarName}} {{.ModelName}}
+		if err := db.ScanRows(rows, &{{.VarName}}); err != nil {
+			return err
+		}
+		if err := fn(&{{.VarName}}); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+{{- if eq .Pagination "cursor"}}
+
+// Page{{.PluralName}} retrieves up to limit {{.PluralName}} from the position of
+// keyset, and the cursors of the next and previous pages; scopes apply as in
+// GetAll{{.PluralName}}
+func Page{{.PluralName}}(db *gorm.DB, keyset *query.Keyset, limit int, scopes ...func(*gorm.DB) *gorm.DB) ([]{{.ModelName}}, string, string, error) {
+	var {{.PluralVarName}} []{{.ModelName}}
+	next, prev, err := keyset.Find(db.Model(&{{.ModelName}}{}).Scopes(scopes...), &{{.PluralVarName}}, limit)
+	return {{.PluralVarName}}, next, prev, err
+}
+
+// Count{{.PluralName}} counts the {{.PluralName}} matched by db
+func Count{{.PluralName}}(db *gorm.DB) (int64, error) {
+	var count int64
+	err := db.Model(&{{.ModelName}}{}).Count(&count).Error
+	return count, err
 }
+{{- end}}
 
-// BatchDelete deletes multiple {{.ModelName}}s
-func BatchDelete{{.ModelName}}s(db *gorm.DB, ids []uint, soft bool) error {
+// BatchDelete deletes multiple {{.PluralName}}
+func BatchDelete{{.PluralName}}(db *gorm.DB, ids []{{.IDType}}, soft bool) error {
 	if soft {
-		return db.Delete(&{{.ModelName}}{}, ids).Error
+		return db.Delete(&{{.ModelName}}{}, "id IN ?", ids).Error
 	}
-	return db.Unscoped().Delete(&{{.ModelName}}{}, ids).Error
+	return db.Unscoped().Delete(&{{.ModelName}}{}, "id IN ?", ids).Error
 }
 
 // ToMap converts {{.ModelName}} to map for JSON serialization
 func (m *{{.ModelName}}) ToMap() map[string]interface{} {
 	return map[string]interface{}{
 		"id":          m.ID,
-		"name":        m.Name,
-		"description": m.Description,
+{{- range .Fields}}
+		"{{.JSONName}}": m.{{.Name}},
+{{- end}}
+{{- range .Relations}}
+		"{{.Include}}": m.{{.Name}},
+{{- end}}
 		"created_at":  m.CreatedAt,
 		"updated_at":  m.UpdatedAt,
 		"deleted_at":  m.DeletedAt,
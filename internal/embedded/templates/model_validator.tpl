@@ -0,0 +1,25 @@
+package {{or .Package "models"}}
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate is shared by the Validate methods of the models in this package
+var validate = newValidator()
+
+// newValidator returns a validator reporting fields by their JSON names, the
+// names clients send
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
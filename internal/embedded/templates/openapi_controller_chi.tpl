@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+{{if .UsesPathParams}}
+	"github.com/go-chi/chi/v5"{{end}}
+	"gorm.io/gorm"
+	"{{.ModulePath}}/app/httperr"{{if .UsesModels}}
+	"{{.ModulePath}}/app/models"{{end}}{{if .UsesRequests}}
+	"{{.ModulePath}}/app/requests"{{end}}
+)
+{{with .Controller}}
+// {{.Name}}Controller handles the {{.Name}} operations of the OpenAPI spec
+type {{.Name}}Controller struct {
+	DB *gorm.DB
+}
+
+// New{{.Name}}Controller creates a new {{.Name}}Controller instance
+func New{{.Name}}Controller(db *gorm.DB) *{{.Name}}Controller {
+	return &{{.Name}}Controller{DB: db}
+}
+{{range .Operations}}
+// {{.Handler}} handles {{.Method}} {{.Path}}{{if .Summary}}
+// {{.Summary}}{{end}}
+func (c *{{$.Controller.Name}}Controller) {{.Handler}}(w http.ResponseWriter, r *http.Request) {
+{{- range .PathParams}}
+	{{.VarName}} := chi.URLParam(r, "{{.Name}}")
+{{- end}}
+{{- if .RequestType}}{{if .PathParams}}
+{{end}}
+	var request {{.RequestType}}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		httperr.Write(w, r, httperr.BadRequest(err.Error()))
+		return
+	}
+{{- if .Validates}}
+
+	if err := request.Validate(); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+{{- end}}{{end}}
+{{if or .PathParams .RequestType}}
+{{end}}	// TODO: Implement {{.Handler}} and respond with status {{.Status}}
+{{range .PathParams}}	_ = {{.VarName}}
+{{end}}{{if .RequestType}}	_ = request
+{{end}}	httperr.Write(w, r, httperr.NotImplemented("{{.Handler}} is not implemented yet"))
+}
+{{end}}
+// Helper methods for JSON responses
+
+func (c *{{.Name}}Controller) jsonResponse(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+{{end}}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"{{.ModulePath}}/app/httperr"{{if .UsesModels}}
+	"{{.ModulePath}}/app/models"{{end}}{{if .UsesRequests}}
+	"{{.ModulePath}}/app/requests"{{end}}
+)
+{{with .Controller}}
+// {{.Name}}Controller handles the {{.Name}} operations of the OpenAPI spec
+type {{.Name}}Controller struct {
+	DB *gorm.DB
+}
+
+// New{{.Name}}Controller creates a new {{.Name}}Controller instance
+func New{{.Name}}Controller(db *gorm.DB) *{{.Name}}Controller {
+	return &{{.Name}}Controller{DB: db}
+}
+{{range .Operations}}
+// {{.Handler}} handles {{.Method}} {{.Path}}{{if .Summary}}
+// {{.Summary}}{{end}}
+func (c *{{$.Controller.Name}}Controller) {{.Handler}}(ctx *gin.Context) {
+{{- range .PathParams}}
+	{{.VarName}} := ctx.Param("{{.Name}}")
+{{- end}}
+{{- if .RequestType}}{{if .PathParams}}
+{{end}}
+	var request {{.RequestType}}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, httperr.BadRequest(err.Error()))
+		return
+	}
+{{- if .Validates}}
+
+	if err := request.Validate(); err != nil {
+		httperr.Write(ctx.Writer, ctx.Request, err)
+		return
+	}
+{{- end}}{{end}}
+{{if or .PathParams .RequestType}}
+{{end}}	// TODO: Implement {{.Handler}} and respond with status {{.Status}}
+{{range .PathParams}}	_ = {{.VarName}}
+{{end}}{{if .RequestType}}	_ = request
+{{end}}	httperr.Write(ctx.Writer, ctx.Request, httperr.NotImplemented("{{.Handler}} is not implemented yet"))
+}
+{{end}}{{end}}
@@ -0,0 +1,11 @@
+package models
+{{with .Struct}}{{if .Imports}}
+import (
+{{range .Imports}}	"{{.}}"
+{{end}})
+{{end}}
+// {{.Name}} {{if .Doc}}{{.Doc}}{{else}}is generated from the {{.Name}} schema{{end}}
+type {{.Name}} struct {
+{{range .Fields}}	{{.Name}} {{.Type}} `json:"{{.JSONName}}"{{if .Validate}} validate:"{{.Validate}}"{{end}}`
+{{end}}}
+{{end}}
@@ -0,0 +1,39 @@
+package requests
+
+import (
+	"reflect"
+	"strings"
+{{- if .UsesTime}}
+	"time"
+{{- end}}
+
+	"github.com/go-playground/validator/v10"{{if .UsesModels}}
+	"{{.ModulePath}}/app/models"{{end}}
+)
+
+// validate reports fields by their JSON names, the names clients send
+var validate = newValidator()
+
+// newValidator returns the validator of the request payloads
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+{{range .Requests}}
+// {{.Doc}}
+type {{.Name}} struct {
+{{range .Fields}}	{{.Name}} {{.Type}} `json:"{{.JSONName}}"{{if .Validate}} validate:"{{.Validate}}"{{end}}`
+{{end}}}
+
+// Validate validates the {{.Name}} payload
+func (r *{{.Name}}) Validate() error {
+	return validate.Struct(r)
+}
+{{end}}
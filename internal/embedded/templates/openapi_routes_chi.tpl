@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+	"{{.ModulePath}}/app/controllers"
+)
+
+// RegisterOpenAPIRoutes wires the handlers generated from {{.Spec}}
+func RegisterOpenAPIRoutes(r chi.Router, db *gorm.DB) {
+{{- range $i, $c := .Controllers}}{{if $i}}
+{{end}}
+	{{$c.VarName}} := controllers.New{{$c.Name}}Controller(db)
+{{- range .Operations}}
+	r.{{.ChiMethod}}("{{.Path}}", {{$c.VarName}}.{{.Handler}})
+{{- end}}
+{{- end}}
+}
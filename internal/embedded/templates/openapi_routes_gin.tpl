@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"{{.ModulePath}}/app/controllers"
+)
+
+// RegisterOpenAPIRoutes wires the handlers generated from {{.Spec}}
+func RegisterOpenAPIRoutes(r gin.IRouter, db *gorm.DB) {
+{{- range $i, $c := .Controllers}}{{if $i}}
+{{end}}
+	{{$c.VarName}} := controllers.New{{$c.Name}}Controller(db)
+{{- range .Operations}}
+	r.{{.Method}}("{{.GinPath}}", {{$c.VarName}}.{{.Handler}})
+{{- end}}
+{{- end}}
+}
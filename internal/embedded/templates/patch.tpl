@@ -0,0 +1,364 @@
+// Package patch applies partial updates to records. RFC 7396 JSON Merge
+// Patch and RFC 6902 JSON Patch documents are applied to the JSON form of a
+// record, and only the fields they change are written back.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPatchType is the media type of RFC 6902 documents; other bodies are
+// read as RFC 7396 merge patches (application/merge-patch+json)
+const JSONPatchType = "application/json-patch+json"
+
+// ErrTestFailed is returned when a JSON Patch test operation doesn't match
+var ErrTestFailed = errors.New("test operation failed")
+
+// errPathNotFound is returned for JSON Patch paths that don't exist
+var errPathNotFound = errors.New("path not found")
+
+// ReadOnly lists the fields a patch may not change
+var ReadOnly = map[string]bool{"id": true, "version": true, "created_at": true, "updated_at": true, "deleted_at": true}
+
+// Apply applies the patch in body to the JSON form of record and decodes the
+// result into patched, a pointer to a zero value of record's type. It returns
+// the fields the patch changes, which must be fields of columns that aren't
+// ReadOnly.
+func Apply(record, patched interface{}, body []byte, contentType string, columns map[string]string) ([]string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode record: %v", err)
+	}
+	original, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode record: %v", err)
+	}
+	doc, _ := decode(data)
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == JSONPatchType {
+		doc, err = applyJSONPatch(doc, body)
+	} else {
+		doc, err = applyMergePatch(doc, body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("the patched document must be a JSON object")
+	}
+
+	fields := changed(original.(map[string]interface{}), result)
+	for _, field := range fields {
+		if ReadOnly[field] {
+			return nil, fmt.Errorf("field '%s' is read-only", field)
+		}
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("unknown field '%s'", field)
+		}
+	}
+
+	if data, err = json.Marshal(result); err != nil {
+		return nil, fmt.Errorf("failed to encode patched record: %v", err)
+	}
+	if err := json.Unmarshal(data, patched); err != nil {
+		return nil, fmt.Errorf("invalid patched record: %v", err)
+	}
+	return fields, nil
+}
+
+// applyMergePatch applies an RFC 7396 merge patch to doc
+func applyMergePatch(doc interface{}, body []byte) (interface{}, error) {
+	patch, err := decode(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %v", err)
+	}
+	if _, ok := patch.(map[string]interface{}); !ok {
+		return nil, errors.New("a merge patch must be a JSON object")
+	}
+	return merge(doc, patch), nil
+}
+
+// merge is the MergePatch function of RFC 7396: objects are merged key by
+// key, null removes a key and any other value replaces the target
+func merge(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+		} else {
+			targetObject[key] = merge(targetObject[key], value)
+		}
+	}
+	return targetObject
+}
+
+// operation is a single RFC 6902 operation
+type operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// applyJSONPatch applies the operations of an RFC 6902 JSON Patch to doc in
+// order, stopping at the first that fails
+func applyJSONPatch(doc interface{}, body []byte) (interface{}, error) {
+	var operations []operation
+	if err := json.Unmarshal(body, &operations); err != nil {
+		return nil, fmt.Errorf("invalid JSON patch: %v", err)
+	}
+	for i, op := range operations {
+		var err error
+		if doc, err = op.apply(doc); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+// apply applies op to doc and returns the updated document
+func (op operation) apply(doc interface{}) (interface{}, error) {
+	path, err := pointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, errors.New("missing value")
+		}
+		value, err := decode(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value: %v", err)
+		}
+		if op.Op != "test" {
+			return set(doc, path, op.Op, value)
+		}
+		current, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(current, value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	case "remove":
+		return set(doc, path, op.Op, nil)
+	case "move", "copy":
+		from, err := pointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if op.Op == "move" {
+			if doc, err = set(doc, from, "remove", nil); err != nil {
+				return nil, err
+			}
+		} else {
+			data, _ := json.Marshal(value)
+			value, _ = decode(data) // copies must not share maps and slices
+		}
+		return set(doc, path, "add", value)
+	}
+	return nil, fmt.Errorf("unknown operation '%s'", op.Op)
+}
+
+// pointer splits an RFC 6901 JSON pointer into its reference tokens
+func pointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("invalid path '%s'", path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	for i, token := range tokens {
+		tokens[i] = unescape.Replace(token)
+	}
+	return tokens, nil
+}
+
+// get returns the value at path
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, errPathNotFound
+			}
+			doc = value
+		case []interface{}:
+			i, err := index(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, errPathNotFound
+		}
+	}
+	return doc, nil
+}
+
+// set adds, replaces or removes the value at path and returns the updated
+// document; only add may create a member or insert an array element
+func set(doc interface{}, path []string, op string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		if op == "remove" {
+			return nil, errors.New("cannot remove the whole document")
+		}
+		return value, nil
+	}
+
+	token, rest := path[0], path[1:]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok && (len(rest) > 0 || op != "add") {
+			return nil, errPathNotFound
+		}
+		if len(rest) == 0 {
+			if op == "remove" {
+				delete(node, token)
+			} else {
+				node[token] = value
+			}
+			return node, nil
+		}
+		updated, err := set(child, rest, op, value)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = updated
+		return node, nil
+	case []interface{}:
+		if len(rest) == 0 && op == "add" {
+			i := len(node)
+			if token != "-" {
+				var err error
+				if i, err = index(token, len(node)+1); err != nil {
+					return nil, err
+				}
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		}
+		i, err := index(token, len(node))
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) == 0 {
+			if op == "remove" {
+				return append(node[:i], node[i+1:]...), nil
+			}
+			node[i] = value
+			return node, nil
+		}
+		updated, err := set(node[i], rest, op, value)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = updated
+		return node, nil
+	}
+	return nil, errPathNotFound
+}
+
+// index parses an array index below n
+func index(token string, n int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= n || (len(token) > 1 && token[0] == '0') {
+		return 0, errPathNotFound
+	}
+	return i, nil
+}
+
+// changed returns the sorted top-level fields whose values differ between
+// the original and the patched document
+func changed(original, patched map[string]interface{}) []string {
+	var fields []string
+	for field, value := range patched {
+		if current, ok := original[field]; !ok || !equal(current, value) {
+			fields = append(fields, field)
+		}
+	}
+	for field := range original {
+		if _, ok := patched[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// equal compares decoded JSON values, numbers by value
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, errA := a.Float64()
+		y, errB := b.Float64()
+		return errA == nil && errB == nil && x == y
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			if other, ok := b[key]; !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// decode parses a JSON value, keeping numbers as json.Number so that large
+// integers survive the round trip
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
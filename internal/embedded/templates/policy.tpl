@@ -0,0 +1,44 @@
+package {{or .Package "policies"}}
+
+import (
+	{{.ModelsImport}}
+)
+
+// {{.ModelName}}Policy authorizes actions on {{.ModelName}} records.
+//
+// Every method receives the current user (the value your auth middleware
+// stored under the "user" context key, or nil for anonymous requests) and
+// the record being acted upon. The record is nil for collection endpoints
+// such as Index and Search. BatchDelete checks every stored record it
+// deletes; UpdateOrCreate checks the stored record its conditions match with
+// CanUpdate, or the new record with CanCreate when none does.
+type {{.ModelName}}Policy struct{}
+
+// New{{.ModelName}}Policy creates a new {{.ModelName}}Policy instance
+func New{{.ModelName}}Policy() *{{.ModelName}}Policy {
+	return &{{.ModelName}}Policy{}
+}
+
+// CanView determines whether the user may view the {{.ModelName}}
+func (p *{{.ModelName}}Policy) CanView(user interface{}, {{.VarName}} *models.{{.ModelName}}) bool {
+	// TODO: Restrict read access if needed
+	return true
+}
+
+// CanCreate determines whether the user may create the {{.ModelName}}
+func (p *{{.ModelName}}Policy) CanCreate(user interface{}, {{.VarName}} *models.{{.ModelName}}) bool {
+	// TODO: Implement your authorization rules
+	return user != nil
+}
+
+// CanUpdate determines whether the user may update the {{.ModelName}}
+func (p *{{.ModelName}}Policy) CanUpdate(user interface{}, {{.VarName}} *models.{{.ModelName}}) bool {
+	// TODO: Implement your authorization rules (e.g. ownership checks)
+	return user != nil
+}
+
+// CanDelete determines whether the user may delete the {{.ModelName}}
+func (p *{{.ModelName}}Policy) CanDelete(user interface{}, {{.VarName}} *models.{{.ModelName}}) bool {
+	// TODO: Implement your authorization rules (e.g. ownership checks)
+	return user != nil
+}
@@ -1,8 +1,8 @@
-package services
+package {{or .Package "services"}}
 
 import (
 	"errors"
-	"{{.ProjectName}}/app/models"
+	{{.ModelsImport}}
 )
 
 // {{.ModelName}}Service handles business logic for {{.ModelName}}
@@ -18,43 +18,43 @@ func New{{.ModelName}}Service() *{{.ModelName}}Service {
 	}
 }
 
-// GetAll{{.ModelName}}s retrieves all {{.ModelName}}s
-func (s *{{.ModelName}}Service) GetAll{{.ModelName}}s() ([]models.{{.ModelName}}, error) {
-	var {{.TableName}} []models.{{.ModelName}}
+// GetAll{{.PluralName}} retrieves all {{.PluralName}}
+func (s *{{.ModelName}}Service) GetAll{{.PluralName}}() ([]models.{{.ModelName}}, error) {
+	var {{.PluralVarName}} []models.{{.ModelName}}
 	
 	// TODO: Implement business logic
 	// Example: Apply filters, sorting, pagination
-	// if err := s.db.Find(&{{.TableName}}).Error; err != nil {
+	// if err := s.db.Find(&{{.PluralVarName}}).Error; err != nil {
 	//     return nil, err
 	// }
 	
-	return {{.TableName}}, nil
+	return {{.PluralVarName}}, nil
 }
 
 // Get{{.ModelName}}ByID retrieves a {{.ModelName}} by ID
-func (s *{{.ModelName}}Service) Get{{.ModelName}}ByID(id uint) (*models.{{.ModelName}}, error) {
-	var {{.ModelName}} models.{{.ModelName}}
+func (s *{{.ModelName}}Service) Get{{.ModelName}}ByID(id {{.IDType}}) (*models.{{.ModelName}}, error) {
+	var {{.VarName}} models.{{.ModelName}}
 	
 	// TODO: Implement business logic
-	// if err := s.db.First(&{{.ModelName}}, id).Error; err != nil {
+	// if err := s.db.First(&{{.VarName}}, id).Error; err != nil {
 	//     if errors.Is(err, gorm.ErrRecordNotFound) {
 	//         return nil, errors.New("{{.ModelName}} not found")
 	//     }
 	//     return nil, err
 	// }
 	
-	return &{{.ModelName}}, nil
+	return &{{.VarName}}, nil
 }
 
 // Create{{.ModelName}} creates a new {{.ModelName}}
-func (s *{{.ModelName}}Service) Create{{.ModelName}}({{.ModelName}} *models.{{.ModelName}}) error {
-	// TODO: Implement validation and business logic
-	if {{.ModelName}}.Name == "" {
-		return errors.New("name is required")
+func (s *{{.ModelName}}Service) Create{{.ModelName}}({{.VarName}} *models.{{.ModelName}}) error {
+	// TODO: Implement business logic
+	if err := s.Validate{{.ModelName}}({{.VarName}}); err != nil {
+		return err
 	}
 	
 	// TODO: Save to database
-	// if err := s.db.Create({{.ModelName}}).Error; err != nil {
+	// if err := s.db.Create({{.VarName}}).Error; err != nil {
 	//     return err
 	// }
 	
@@ -62,7 +62,7 @@ func (s *{{.ModelName}}Service) Create{{.ModelName}}({{.ModelName}} *models.{{.M
 }
 
 // Update{{.ModelName}} updates an existing {{.ModelName}}
-func (s *{{.ModelName}}Service) Update{{.ModelName}}(id uint, updates *models.{{.ModelName}}) error {
+func (s *{{.ModelName}}Service) Update{{.ModelName}}(id {{.IDType}}, updates *models.{{.ModelName}}) error {
 	// TODO: Implement validation and business logic
 	existing, err := s.Get{{.ModelName}}ByID(id)
 	if err != nil {
@@ -70,12 +70,13 @@ func (s *{{.ModelName}}Service) Update{{.ModelName}}(id uint, updates *models.{{
 	}
 	
 	// Apply updates
-	if updates.Name != "" {
-		existing.Name = updates.Name
-	}
-	if updates.Description != "" {
-		existing.Description = updates.Description
+{{- range .StringFields}}
+	if updates.{{.Name}} != "" {
+		existing.{{.Name}} = updates.{{.Name}}
 	}
+{{- else}}
+	_ = existing // TODO: copy the fields of updates
+{{- end}}
 	
 	// TODO: Save to database
 	// if err := s.db.Save(existing).Error; err != nil {
@@ -86,7 +87,7 @@ func (s *{{.ModelName}}Service) Update{{.ModelName}}(id uint, updates *models.{{
 }
 
 // Delete{{.ModelName}} deletes a {{.ModelName}}
-func (s *{{.ModelName}}Service) Delete{{.ModelName}}(id uint) error {
+func (s *{{.ModelName}}Service) Delete{{.ModelName}}(id {{.IDType}}) error {
 	// TODO: Implement business logic and validation
 	// Check if {{.ModelName}} exists
 	_, err := s.Get{{.ModelName}}ByID(id)
@@ -103,10 +104,15 @@ func (s *{{.ModelName}}Service) Delete{{.ModelName}}(id uint) error {
 }
 
 // Validate{{.ModelName}} validates {{.ModelName}} data
-func (s *{{.ModelName}}Service) Validate{{.ModelName}}({{.ModelName}} *models.{{.ModelName}}) error {
-	if {{.ModelName}}.Name == "" {
-		return errors.New("name is required")
+func (s *{{.ModelName}}Service) Validate{{.ModelName}}({{.VarName}} *models.{{.ModelName}}) error {
+	if {{.VarName}} == nil {
+		return errors.New("{{snake .ModelName}} is required")
+	}
+{{- range .StringFields}}{{if .Required}}
+	if {{$.VarName}}.{{.Name}} == "" {
+		return errors.New("{{.JSONName}} is required")
 	}
+{{- end}}{{end}}
 	
 	// Add more validation rules as needed
 	
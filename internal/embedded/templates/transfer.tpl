@@ -0,0 +1,333 @@
+// Package transfer streams records to and from CSV and JSON Lines for the
+// generated export and import endpoints. Records travel in their JSON form,
+// so the columns of a CSV file are the JSON fields of a model.
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"{{.ModulePath}}/app/httperr"
+)
+
+// Formats of the export and import endpoints
+const (
+	CSV   = "csv"
+	JSONL = "jsonl"
+)
+
+// MaxLine is the longest JSON line an import accepts
+const MaxLine = 1 << 20
+
+// MaxErrors is the number of failed lines a report lists; later failures are
+// only counted
+const MaxErrors = 1000
+
+// contentTypes maps the formats to the media types they are sent as
+var contentTypes = map[string]string{CSV: "text/csv", JSONL: "application/x-ndjson"}
+
+// Format returns the format named by param or, when param is empty, by the
+// media type of contentType. Without either it defaults to CSV.
+func Format(param, contentType string) (string, error) {
+	if param != "" {
+		if _, ok := contentTypes[param]; !ok {
+			return "", fmt.Errorf("unknown format '%s', expected csv or jsonl", param)
+		}
+		return param, nil
+	}
+	if contentType == "" {
+		return CSV, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return CSV, nil
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return JSONL, nil
+	}
+	return "", fmt.Errorf("unsupported content type '%s', send text/csv or application/x-ndjson", mediaType)
+}
+
+// Writer streams records as CSV rows or JSON lines. Nothing is sent before
+// the first record or Flush, so errors until then can still be answered with
+// a problem response.
+type Writer struct {
+	w       http.ResponseWriter
+	format  string
+	name    string   // file name without extension
+	fields  []string // CSV columns
+	csv     *csv.Writer
+	started bool
+}
+
+// NewWriter returns a writer sending a name.csv or name.jsonl attachment; CSV
+// rows hold the given fields
+func NewWriter(w http.ResponseWriter, format, name string, fields []string) *Writer {
+	return &Writer{w: w, format: format, name: name, fields: fields}
+}
+
+// Started reports whether the response is under way
+func (w *Writer) Started() bool { return w.started }
+
+// Write sends a record
+func (w *Writer) Write(record interface{}) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %v", err)
+	}
+	if w.format == JSONL {
+		_, err = w.w.Write(append(data, '\n'))
+		return err
+	}
+
+	values, err := decodeObject(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode record: %v", err)
+	}
+	row := make([]string, len(w.fields))
+	for i, field := range w.fields {
+		row[i] = cell(values[field])
+	}
+	return w.csv.Write(row)
+}
+
+// Flush sends what is buffered, and the headers of an empty export
+func (w *Writer) Flush() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
+
+// start sends the headers and the CSV header row, once
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	w.w.Header().Set("Content-Type", contentTypes[w.format]+"; charset=utf-8")
+	w.w.Header().Set("Content-Disposition", `attachment; filename="`+w.name+"."+w.format+`"`)
+	w.w.WriteHeader(http.StatusOK)
+	if w.format == CSV {
+		w.csv = csv.NewWriter(w.w)
+		return w.csv.Write(w.fields)
+	}
+	return nil
+}
+
+// cell formats a decoded JSON value as a CSV cell: null is empty, strings and
+// scalars are written as they are and objects and arrays as JSON
+func cell(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// Reader reads the records of a CSV or JSON Lines body as JSON objects. A CSV
+// body starts with a header row of JSON fields; its cells are typed after the
+// fields of a prototype record, and empty cells are left out.
+type Reader struct {
+	format string
+	csv    *csv.Reader
+	lines  *bufio.Scanner
+	header []string
+	kinds  map[string]interface{} // field values of the prototype
+	line   int
+	done   bool
+}
+
+// NewReader returns a reader of body; prototype is a zero record of the model
+// the rows are decoded into
+func NewReader(body io.Reader, format string, prototype interface{}) (*Reader, error) {
+	r := &Reader{format: format}
+	if format == JSONL {
+		r.lines = bufio.NewScanner(body)
+		r.lines.Buffer(make([]byte, 64*1024), MaxLine)
+		return r, nil
+	}
+
+	data, err := json.Marshal(prototype)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode prototype: %v", err)
+	}
+	if r.kinds, err = decodeObject(data); err != nil {
+		return nil, fmt.Errorf("failed to decode prototype: %v", err)
+	}
+	r.csv = csv.NewReader(body)
+	header, err := r.csv.Read()
+	if err == io.EOF {
+		return nil, errors.New("the CSV file has no header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %v", err)
+	}
+	for i, field := range header {
+		if i == 0 {
+			field = strings.TrimPrefix(field, "\ufeff") // byte order mark of spreadsheet exports
+		}
+		field = strings.TrimSpace(field)
+		if _, ok := r.kinds[field]; !ok {
+			return nil, fmt.Errorf("unknown column '%s'", field)
+		}
+		header[i] = field
+	}
+	r.header = header
+	return r, nil
+}
+
+// Next returns the next record and the line it starts on, or io.EOF after the
+// last one. Other errors concern that line only, and reading may go on.
+func (r *Reader) Next() (int, json.RawMessage, error) {
+	if r.done {
+		return 0, nil, io.EOF
+	}
+	if r.format == JSONL {
+		return r.nextLine()
+	}
+
+	record, err := r.csv.Read()
+	if err == io.EOF {
+		return 0, nil, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.StartLine, nil, parseErr.Err
+	}
+	if err != nil {
+		r.done = true
+		return 0, nil, err
+	}
+
+	line, _ := r.csv.FieldPos(0)
+	object := map[string]json.RawMessage{}
+	for i, value := range record {
+		if value == "" {
+			continue
+		}
+		field := r.header[i]
+		if object[field], err = typed(r.kinds[field], value); err != nil {
+			return line, nil, fmt.Errorf("%s: %v", field, err)
+		}
+	}
+	data, err := json.Marshal(object)
+	return line, data, err
+}
+
+// nextLine returns the next non-blank line of a JSON Lines body
+func (r *Reader) nextLine() (int, json.RawMessage, error) {
+	for r.lines.Scan() {
+		r.line++
+		text := bytes.TrimSpace(r.lines.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		if !json.Valid(text) {
+			return r.line, nil, errors.New("invalid JSON")
+		}
+		return r.line, append(json.RawMessage(nil), text...), nil
+	}
+	r.done = true
+	if err := r.lines.Err(); err != nil {
+		// The scanner can't go past a line that is too long
+		return r.line + 1, nil, err
+	}
+	return 0, nil, io.EOF
+}
+
+// typed converts a CSV cell to the JSON value of a field whose prototype value
+// is kind; nullable fields, objects and arrays take JSON or a string
+func typed(kind interface{}, value string) (json.RawMessage, error) {
+	switch kind.(type) {
+	case string:
+		return json.Marshal(value)
+	case json.Number:
+		if _, err := strconv.ParseFloat(value, 64); err != nil || !json.Valid([]byte(value)) {
+			return nil, fmt.Errorf("'%s' is not a number", value)
+		}
+		return json.RawMessage(value), nil
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a boolean", value)
+		}
+		return json.Marshal(b)
+	}
+	if json.Valid([]byte(value)) {
+		return json.RawMessage(value), nil
+	}
+	return json.Marshal(value)
+}
+
+// decodeObject parses a JSON object, keeping numbers as json.Number
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// Report is the outcome of an import, with the lines that failed
+type Report struct {
+	Imported int         `json:"imported"`
+	Failed   int         `json:"failed"`
+	Errors   []LineError `json:"errors,omitempty"` // the first MaxErrors failures
+}
+
+// LineError is a line of the input that wasn't imported
+type LineError struct {
+	Line   int                 `json:"line"`
+	Status int                 `json:"status"`
+	Detail string              `json:"detail,omitempty"`
+	Errors map[string][]string `json:"errors,omitempty"` // validation messages by JSON field
+}
+
+// Fail records that line failed with err, mapped to a status like
+// httperr.Write does, with validation messages in lang
+func (r *Report) Fail(line int, err error, lang string) {
+	problem := httperr.FromError(err, lang)
+	if problem.Status == http.StatusInternalServerError {
+		log.Printf("[ERROR] import line %d: %v", line, err)
+	}
+	r.Failed++
+	if len(r.Errors) < MaxErrors {
+		r.Errors = append(r.Errors, LineError{Line: line, Status: problem.Status, Detail: problem.Detail, Errors: problem.Errors})
+	}
+}
+
+// Status returns the status of the import: 201 when every line was imported,
+// 207 Multi-Status otherwise
+func (r *Report) Status() int {
+	if r.Failed > 0 {
+		return http.StatusMultiStatus
+	}
+	return http.StatusCreated
+}
@@ -0,0 +1,110 @@
+package gomod
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Module is a Go module on disk
+type Module struct {
+	Path string // module path, e.g. "github.com/acme/orders"
+	Dir  string // absolute directory holding go.mod
+}
+
+// Find returns the module containing dir, which need not exist yet: the
+// nearest go.mod in dir or its parents. At the root of a go.work workspace
+// the search ends with the used module containing dir, if any. Find returns
+// nil when dir belongs to no module.
+func Find(dir string) (*Module, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for d := abs; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return Load(d)
+		}
+		if work := filepath.Join(d, "go.work"); os.Getenv("GOWORK") != "off" && fileExists(work) {
+			return findInWorkspace(work, abs)
+		}
+		if filepath.Dir(d) == d {
+			return nil, nil
+		}
+	}
+}
+
+// Load reads the module whose go.mod is in dir
+func Load(dir string) (*Module, error) {
+	goMod := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(goMod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", goMod, err)
+	}
+	modulePath := modfile.ModulePath(data)
+	if modulePath == "" {
+		return nil, fmt.Errorf("%s has no module directive", goMod)
+	}
+	return &Module{Path: modulePath, Dir: dir}, nil
+}
+
+// ImportPath returns the import path of the package in dir, which must be
+// inside the module
+func (m *Module) ImportPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(m.Dir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside module %s (%s)", dir, m.Path, m.Dir)
+	}
+	if rel == "." {
+		return m.Path, nil
+	}
+	return m.Path + "/" + filepath.ToSlash(rel), nil
+}
+
+// findInWorkspace returns the module used by the go.work file at work that
+// contains dir, preferring the innermost one
+func findInWorkspace(work, dir string) (*Module, error) {
+	data, err := os.ReadFile(work)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", work, err)
+	}
+	file, err := modfile.ParseWork(work, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", work, err)
+	}
+
+	best := ""
+	for _, use := range file.Use {
+		useDir := filepath.Clean(filepath.Join(filepath.Dir(work), filepath.FromSlash(use.Path)))
+		if filepath.IsAbs(use.Path) {
+			useDir = filepath.Clean(use.Path)
+		}
+		if contains(useDir, dir) && len(useDir) > len(best) {
+			best = useDir
+		}
+	}
+	if best == "" {
+		return nil, nil
+	}
+	return Load(best)
+}
+
+// contains reports whether path is dir or inside it
+func contains(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
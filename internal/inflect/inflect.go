@@ -0,0 +1,289 @@
+package inflect
+
+import (
+	"go/token"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// rule rewrites a lower case word matching pattern
+type rule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+var (
+	// uncountables are returned unchanged by Plural and Singular
+	uncountables = map[string]bool{
+		"data": true, "deer": true, "equipment": true, "feedback": true, "fish": true,
+		"hardware": true, "information": true, "metadata": true, "money": true, "news": true,
+		"police": true, "rice": true, "series": true, "sheep": true, "software": true,
+		"species": true, "staff": true,
+	}
+
+	// irregulars maps singular to plural forms
+	irregulars = map[string]string{
+		"child": "children", "criterion": "criteria", "foot": "feet", "goose": "geese",
+		"man": "men", "mouse": "mice", "ox": "oxen", "person": "people", "tooth": "teeth",
+		"woman": "women",
+	}
+
+	// irregularPlurals maps plural to singular forms
+	irregularPlurals = reverse(irregulars)
+
+	pluralRules = compile([][2]string{
+		{`(quiz)$`, "${1}zes"},
+		{`(matr|vert|ind)(?:ix|ex)$`, "${1}ices"},
+		{`(alumn|cact|fung|nucle|radi|stimul|syllab)us$`, "${1}i"},
+		{`(alias|status|bus)$`, "${1}es"},
+		{`^(atla|bia|canva|ga|len)s$`, "${1}ses"},
+		{`(em|gn|gur|haik|men|tut)us$`, "${1}us"},
+		{`us$`, "uses"},
+		{`(ax|test|cris)is$`, "${1}es"},
+		{`(x|ch|ss|sh|z)$`, "${1}es"},
+		{`([^aeiouy]|qu)y$`, "${1}ies"},
+		{`(hive)$`, "${1}s"},
+		{`(lea|loa|thie)f$`, "${1}ves"},
+		{`([lr])f$`, "${1}ves"},
+		{`(kni|wi|li)fe$`, "${1}ves"},
+		{`sis$`, "ses"},
+		{`([ti])um$`, "${1}a"},
+		{`(buffal|tomat|potat|her|ech)o$`, "${1}oes"},
+		{`s$`, "s"},
+		{`$`, "s"},
+	})
+
+	singularRules = compile([][2]string{
+		{`(quiz)zes$`, "${1}"},
+		{`(matr)ices$`, "${1}ix"},
+		{`(vert|ind)ices$`, "${1}ex"},
+		{`(alumn|cact|fung|nucle|radi|stimul|syllab)(?:us|i)$`, "${1}us"},
+		{`(alias|status|bus)(?:es)?$`, "${1}"},
+		{`^(atla|bia|canva|ga|len)s(?:es)?$`, "${1}s"},
+		{`(em|gn|gur|haik|men|tut)us$`, "${1}u"},
+		{`(apparat|bon|camp|cens|chor|circ|corp|foc|geni|minibus|nex|octop|plex|prospect|sin|surpl|thesaur|vir|walr)(?:us|uses)$`, "${1}us"},
+		{`([^aeiou])us$`, "${1}us"},
+		{`(cris|ax|test)(?:is|es)$`, "${1}is"},
+		{`(shoe)s$`, "${1}"},
+		{`(buffal|tomat|potat|her|ech)oes$`, "${1}o"},
+		{`(x|ch|ss|sh|z)es$`, "${1}"},
+		{`(m)ovies$`, "${1}ovie"},
+		{`([^aeiouy]|qu)ies$`, "${1}y"},
+		{`(hive)s$`, "${1}"},
+		{`(tive)s$`, "${1}"},
+		{`(olive)s$`, "${1}"},
+		{`(lea|loa|thie)ves$`, "${1}f"},
+		{`([lr])ves$`, "${1}f"},
+		{`(kni|wi|li)ves$`, "${1}fe"},
+		{`(analy|ba|diagno|parenthe|progno|synop|the)(?:sis|ses)$`, "${1}sis"},
+		{`([ti])a$`, "${1}um"},
+		{`(ss)$`, "${1}"},
+		{`s$`, ""},
+	})
+)
+
+// initialisms are kept upper case in Go identifiers
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "id": true, "ip": true, "json": true,
+	"sql": true, "uid": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// Plural returns the plural form of the last word in s ("UserProfile" -> "UserProfiles")
+func Plural(s string) string {
+	return inflectLastWord(s, func(word string) string {
+		if irregular, ok := irregulars[word]; ok {
+			return irregular
+		}
+		return applyRules(pluralRules, word)
+	})
+}
+
+// Singular returns the singular form of the last word in s ("categories" -> "category")
+func Singular(s string) string {
+	return inflectLastWord(s, func(word string) string {
+		if irregular, ok := irregularPlurals[word]; ok {
+			return irregular
+		}
+		return applyRules(singularRules, word)
+	})
+}
+
+// Pascal converts s to an exported Go identifier ("user_profile" -> "UserProfile",
+// "api_key" -> "APIKey")
+func Pascal(s string) string {
+	var b strings.Builder
+	for _, w := range Words(s) {
+		lower := strings.ToLower(w)
+		if initialisms[lower] {
+			b.WriteString(strings.ToUpper(lower))
+			continue
+		}
+		b.WriteString(upperFirst(lower))
+	}
+	return b.String()
+}
+
+// Camel converts s to an unexported Go identifier ("UserProfile" -> "userProfile",
+// "APIKey" -> "apiKey")
+func Camel(s string) string {
+	words := Words(s)
+	if len(words) == 0 {
+		return ""
+	}
+	return strings.ToLower(words[0]) + Pascal(strings.Join(words[1:], "_"))
+}
+
+// Snake converts s to snake_case ("UserProfile" -> "user_profile")
+func Snake(s string) string {
+	return strings.ToLower(strings.Join(Words(s), "_"))
+}
+
+// Kebab converts s to kebab-case ("UserProfile" -> "user-profile")
+func Kebab(s string) string {
+	return strings.ToLower(strings.Join(Words(s), "-"))
+}
+
+// Table returns the conventional table name for a model ("Category" -> "categories")
+func Table(model string) string {
+	return Snake(Plural(model))
+}
+
+// Route returns the conventional route segment for a model ("UserProfile" -> "user-profiles")
+func Route(model string) string {
+	return Kebab(Plural(model))
+}
+
+// Variable returns a camelCase identifier that is safe to declare next to the
+// packages and names used by the generated code
+func Variable(s string) string {
+	name := Camel(s)
+	if token.IsKeyword(name) || reservedVariables[name] {
+		name += "Model"
+	}
+	return name
+}
+
+// reservedVariables would shadow packages or locals of the generated code
+var reservedVariables = map[string]bool{
+	"c": true, "chi": true, "ctx": true, "db": true, "err": true, "errors": true,
+	"fmt": true, "gin": true, "gorm": true, "http": true, "id": true, "io": true,
+	"json": true, "m": true, "models": true, "original": true, "patch": true,
+	"policies": true, "query": true, "r": true, "request": true, "strconv": true,
+	"strings": true, "time": true, "user": true, "validate": true, "validator": true,
+	"w": true,
+}
+
+// Words splits an identifier into its words, handling snake_case, kebab-case,
+// spaces, camelCase and acronyms ("HTTPServer" -> ["HTTP", "Server"])
+func Words(s string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(s)
+
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+		case unicode.IsUpper(r) && len(current) > 0 &&
+			(unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) || (startsWord(runes, i+1) && !isAcronymPlural(runes, i+1))):
+			words = append(words, string(current))
+			current = []rune{r}
+		default:
+			current = append(current, r)
+		}
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
+
+// startsWord reports whether runes[i] is a lower case letter continuing a word
+func startsWord(runes []rune, i int) bool {
+	return i < len(runes) && unicode.IsLower(runes[i])
+}
+
+// isAcronymPlural reports whether runes[i] is the "s" of a pluralized acronym
+// such as "APIs" or "IDs"
+func isAcronymPlural(runes []rune, i int) bool {
+	return runes[i] == 's' && !startsWord(runes, i+1)
+}
+
+// inflectLastWord applies fn to the last word of s, preserving its case
+func inflectLastWord(s string, fn func(string) string) string {
+	words := Words(s)
+	if len(words) == 0 {
+		return s
+	}
+	last := words[len(words)-1]
+	idx := strings.LastIndex(s, last)
+	lower := strings.ToLower(last)
+
+	if uncountables[lower] {
+		return s
+	}
+
+	var inflected string
+	switch acronym := strings.TrimSuffix(last, "s"); {
+	case len(acronym) > 1 && acronym == strings.ToUpper(acronym):
+		// Acronyms keep their case: "API" <-> "APIs"
+		result := fn(lower)
+		switch {
+		case result == lower:
+			return s
+		case len(result) > len(lower):
+			inflected = acronym + "s"
+		default:
+			inflected = acronym
+		}
+	default:
+		inflected = fn(lower)
+		if first, _ := utf8.DecodeRuneInString(last); unicode.IsUpper(first) {
+			inflected = upperFirst(inflected)
+		}
+	}
+
+	return s[:idx] + inflected + s[idx+len(last):]
+}
+
+// upperFirst upper cases the first letter of s, which may take several bytes
+func upperFirst(s string) string {
+	first, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(first)) + s[size:]
+}
+
+// applyRules rewrites word with the first matching rule
+func applyRules(rules []rule, word string) string {
+	for _, r := range rules {
+		if r.pattern.MatchString(word) {
+			return r.pattern.ReplaceAllString(word, r.replacement)
+		}
+	}
+	return word
+}
+
+// compile builds the rule list from pattern/replacement pairs
+func compile(pairs [][2]string) []rule {
+	rules := make([]rule, len(pairs))
+	for i, p := range pairs {
+		rules[i] = rule{regexp.MustCompile(p[0]), p[1]}
+	}
+	return rules
+}
+
+// reverse swaps the keys and values of a map
+func reverse(m map[string]string) map[string]string {
+	r := make(map[string]string, len(m))
+	for k, v := range m {
+		r[v] = k
+	}
+	return r
+}
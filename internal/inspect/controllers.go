@@ -0,0 +1,226 @@
+package inspect
+
+import (
+	"go/ast"
+	"go/token"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"went-plate/internal/inflect"
+)
+
+// Controller describes a generated <Model>Controller type
+type Controller struct {
+	Name      string // e.g. "UserController"
+	Resource  string // e.g. "User"
+	Namespace string // sub-package below the controllers directory, e.g. "admin"
+	Router    string // gin | chi
+	Routes    []Route
+}
+
+// Route maps an HTTP method and path (relative to the resource) to a handler
+type Route struct {
+	Method  string
+	Path    string // e.g. "/{id}/restore"
+	Handler string
+}
+
+// ConventionalRoutes lists the routes exposed by the stock controller templates,
+// keyed by handler name. Gin controllers don't declare their routes, so these
+// are used whenever a controller has no Routes() method.
+var ConventionalRoutes = map[string]Route{
+	"Index":          {Method: http.MethodGet, Path: "/"},
+	"Store":          {Method: http.MethodPost, Path: "/"},
+	"Show":           {Method: http.MethodGet, Path: "/{id}"},
+	"Update":         {Method: http.MethodPut, Path: "/{id}"},
+	"Patch":          {Method: http.MethodPatch, Path: "/{id}"},
+	"Delete":         {Method: http.MethodDelete, Path: "/{id}"},
+	"SoftDelete":     {Method: http.MethodDelete, Path: "/{id}/soft"},
+	"Restore":        {Method: http.MethodPost, Path: "/{id}/restore"},
+	"UpdateOrCreate": {Method: http.MethodPost, Path: "/upsert"},
+	"Search":         {Method: http.MethodGet, Path: "/search"},
+	"BatchDelete":    {Method: http.MethodDelete, Path: "/batch"},
+	"BatchStore":     {Method: http.MethodPost, Path: "/batch"},
+	"BatchUpdate":    {Method: http.MethodPatch, Path: "/batch"},
+	"Export":         {Method: http.MethodGet, Path: "/export"},
+	"Import":         {Method: http.MethodPost, Path: "/import"},
+	"GetByField":     {Method: http.MethodGet, Path: "/by/{field}/{value}"},
+}
+
+// nestedRoute returns the route of a List<Association> handler, which the
+// stock templates generate for has-many associations ("ListComments" ->
+// GET /{id}/comments)
+func nestedRoute(handler string) (Route, bool) {
+	name, ok := strings.CutPrefix(handler, "List")
+	if !ok || name == "" || !unicode.IsUpper(rune(name[0])) {
+		return Route{}, false
+	}
+	return Route{Method: http.MethodGet, Path: "/{id}/" + inflect.Kebab(name), Handler: handler}, true
+}
+
+// ParseControllers parses every Go file in dir and its sub-packages and
+// returns the controllers found there, sorted by namespace and name
+func ParseControllers(dir string) ([]Controller, error) {
+	namespaces, err := packageDirs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Controller
+	for _, ns := range namespaces {
+		controllers, err := parseControllerPackage(filepath.Join(dir, ns), filepath.ToSlash(ns))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, controllers...)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// parseControllerPackage returns the controllers of a single package directory
+func parseControllerPackage(dir, namespace string) ([]Controller, error) {
+	fset := token.NewFileSet()
+	files, err := parseDir(fset, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	controllers := map[string]*Controller{}
+	handlers := map[string][]string{}
+	declared := map[string][]Route{}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				if d.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range d.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if _, ok := ts.Type.(*ast.StructType); !ok || !strings.HasSuffix(ts.Name.Name, "Controller") {
+						continue
+					}
+					controllers[ts.Name.Name] = &Controller{
+						Name:      ts.Name.Name,
+						Resource:  strings.TrimSuffix(ts.Name.Name, "Controller"),
+						Namespace: namespace,
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv == nil || len(d.Recv.List) != 1 || !d.Name.IsExported() {
+					continue
+				}
+				recv := receiverName(d.Recv.List[0].Type)
+				if d.Name.Name == "Routes" {
+					declared[recv] = routesFromBody(d.Body)
+					continue
+				}
+				if router := handlerRouter(d.Type); router != "" {
+					handlers[recv] = append(handlers[recv], d.Name.Name+":"+router)
+				}
+			}
+		}
+	}
+
+	var result []Controller
+	for name, c := range controllers {
+		known := map[string]bool{}
+		for _, h := range handlers[name] {
+			parts := strings.SplitN(h, ":", 2)
+			known[parts[0]] = true
+			c.Router = parts[1]
+		}
+
+		if routes, ok := declared[name]; ok && len(routes) > 0 {
+			c.Routes = routes
+		} else {
+			for handler := range known {
+				if route, ok := ConventionalRoutes[handler]; ok {
+					route.Handler = handler
+					c.Routes = append(c.Routes, route)
+				} else if route, ok := nestedRoute(handler); ok {
+					c.Routes = append(c.Routes, route)
+				}
+			}
+		}
+
+		sort.Slice(c.Routes, func(i, j int) bool {
+			if c.Routes[i].Path != c.Routes[j].Path {
+				return c.Routes[i].Path < c.Routes[j].Path
+			}
+			return c.Routes[i].Method < c.Routes[j].Method
+		})
+		result = append(result, *c)
+	}
+	return result, nil
+}
+
+// handlerRouter reports which router a handler signature belongs to
+func handlerRouter(ft *ast.FuncType) string {
+	var params []string
+	for _, p := range ft.Params.List {
+		n := len(p.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			params = append(params, ExprString(p.Type))
+		}
+	}
+
+	switch {
+	case len(params) == 1 && params[0] == "*gin.Context":
+		return "gin"
+	case len(params) == 2 && params[0] == "http.ResponseWriter" && params[1] == "*http.Request":
+		return "chi"
+	}
+	return ""
+}
+
+// routesFromBody collects r.Get("/path", c.Handler) style registrations
+func routesFromBody(body *ast.BlockStmt) []Route {
+	var routes []Route
+	if body == nil {
+		return routes
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		method := strings.ToUpper(sel.Sel.Name)
+		switch method {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		handler, ok := call.Args[1].(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		path, _ := strconv.Unquote(lit.Value)
+		routes = append(routes, Route{Method: method, Path: path, Handler: handler.Sel.Name})
+		return true
+	})
+	return routes
+}
@@ -0,0 +1,357 @@
+package inspect
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"went-plate/internal/inflect"
+)
+
+// Model describes a struct declared in a models package
+type Model struct {
+	Name      string
+	Namespace string // sub-package below the models directory, e.g. "admin"
+	TableName string
+	Doc       string
+	Fields    []Field
+	Keyset    bool // the package declares Page<Models>, the cursor pagination helper
+	Versioned bool // has a Version column for optimistic locking
+}
+
+// Field describes a single struct field and its tags
+type Field struct {
+	Name      string
+	Type      string // Go type expression, e.g. "string", "*time.Time", "[]Tag"
+	JSONName  string // empty when the field is skipped with json:"-"
+	OmitEmpty bool
+	Validate  string
+	Gorm      string
+	Embedded  bool
+}
+
+// Association describes a field of a model holding another model of its package
+type Association struct {
+	Kind       string // "belongs-to", "has-one", "has-many" or "many-to-many"
+	Field      string // e.g. "Author"
+	Model      string // associated model, e.g. "User"
+	JSONName   string // e.g. "author"
+	ForeignKey string // e.g. "AuthorID"; empty for many-to-many
+	JoinTable  string // join table of many-to-many associations, e.g. "post_tags"
+}
+
+// Associations returns the fields of m holding models of its own package:
+// those found among models, and any tagged with a foreign key or join table.
+// Single values are belongs-to, or has-one when m has no foreign key field
+// for them; slices are has-many, or many-to-many when their gorm tag names a
+// join table.
+func (m Model) Associations(models []Model) []Association {
+	known := map[string]bool{}
+	for _, other := range models {
+		if other.Namespace == m.Namespace {
+			known[other.Name] = true
+		}
+	}
+	fields := map[string]bool{}
+	for _, f := range m.Fields {
+		fields[f.Name] = true
+	}
+
+	var result []Association
+	for _, f := range m.Fields {
+		elem := strings.TrimPrefix(f.Type, "[]")
+		slice := elem != f.Type
+		elem = strings.TrimPrefix(elem, "*")
+		tagged := gormSetting(f.Gorm, "foreignKey") != "" || gormSetting(f.Gorm, "many2many") != ""
+		if f.Embedded || strings.Contains(elem, ".") || !known[elem] && !tagged {
+			continue
+		}
+
+		a := Association{Field: f.Name, Model: elem, JSONName: f.JSONName, ForeignKey: gormSetting(f.Gorm, "foreignKey")}
+		switch {
+		case slice && gormSetting(f.Gorm, "many2many") != "":
+			a.Kind, a.ForeignKey, a.JoinTable = "many-to-many", "", gormSetting(f.Gorm, "many2many")
+		case slice:
+			a.Kind = "has-many"
+			if a.ForeignKey == "" {
+				a.ForeignKey = m.Name + "ID"
+			}
+		default:
+			if a.ForeignKey == "" {
+				a.ForeignKey = f.Name + "ID"
+			}
+			a.Kind = "belongs-to"
+			if !fields[a.ForeignKey] {
+				a.Kind = "has-one"
+			}
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+// gormSetting returns the value of a key:value setting in a gorm tag,
+// matching the key case-insensitively like GORM does
+func gormSetting(tag, key string) string {
+	for _, setting := range strings.Split(tag, ";") {
+		k, v, _ := strings.Cut(setting, ":")
+		if strings.EqualFold(strings.TrimSpace(k), key) {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// ParseModels parses every Go file in dir and its sub-packages and returns
+// the exported structs found there, sorted by namespace and name
+func ParseModels(dir string) ([]Model, error) {
+	namespaces, err := packageDirs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Model
+	for _, ns := range namespaces {
+		models, err := parseModelPackage(filepath.Join(dir, ns), filepath.ToSlash(ns))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, models...)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// parseModelPackage returns the exported structs of a single package directory
+func parseModelPackage(dir, namespace string) ([]Model, error) {
+	fset := token.NewFileSet()
+	files, err := parseDir(fset, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	models := map[string]*Model{}
+	tables := map[string]string{}
+	funcs := map[string]bool{}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				if d.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range d.Specs {
+					ts := spec.(*ast.TypeSpec)
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok || !ts.Name.IsExported() {
+						continue
+					}
+					m := &Model{Name: ts.Name.Name, Namespace: namespace, Doc: docText(d.Doc, ts.Doc)}
+					m.Fields = structFields(st)
+					models[m.Name] = m
+				}
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					funcs[d.Name.Name] = true
+				}
+				if d.Name.Name != "TableName" || d.Recv == nil || len(d.Recv.List) != 1 {
+					continue
+				}
+				if name := receiverName(d.Recv.List[0].Type); name != "" {
+					if table := returnedString(d.Body); table != "" {
+						tables[name] = table
+					}
+				}
+			}
+		}
+	}
+
+	var result []Model
+	for name, m := range models {
+		m.TableName = tables[name]
+		m.Keyset = funcs["Page"+inflect.Plural(name)]
+		for _, f := range m.Fields {
+			if f.Name == "Version" && f.JSONName == "version" {
+				m.Versioned = true
+			}
+		}
+		result = append(result, *m)
+	}
+	return result, nil
+}
+
+// packageDirs returns dir and every sub-directory below it, relative to dir
+// ("" for dir itself)
+func packageDirs(dir string) ([]string, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", dir, err)
+	}
+
+	var dirs []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			rel = ""
+		}
+		dirs = append(dirs, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", dir, err)
+	}
+	return dirs, nil
+}
+
+// parseDir parses all non-test Go files in dir
+func parseDir(fset *token.FileSet, dir string) ([]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", dir, err)
+	}
+
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", filepath.Join(dir, name), err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// structFields extracts the fields of a struct type
+func structFields(st *ast.StructType) []Field {
+	var fields []Field
+	for _, f := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if f.Tag != nil {
+			if raw, err := strconv.Unquote(f.Tag.Value); err == nil {
+				tag = reflect.StructTag(raw)
+			}
+		}
+
+		base := Field{
+			Type:     ExprString(f.Type),
+			Validate: tag.Get("validate"),
+			Gorm:     tag.Get("gorm"),
+		}
+
+		if len(f.Names) == 0 {
+			base.Name = strings.TrimPrefix(base.Type, "*")
+			if idx := strings.LastIndex(base.Name, "."); idx != -1 {
+				base.Name = base.Name[idx+1:]
+			}
+			base.Embedded = true
+			fields = append(fields, base)
+			continue
+		}
+
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			field := base
+			field.Name = ident.Name
+			field.JSONName = ident.Name
+			if jsonTag, ok := tag.Lookup("json"); ok {
+				parts := strings.Split(jsonTag, ",")
+				switch parts[0] {
+				case "-":
+					field.JSONName = ""
+				case "":
+				default:
+					field.JSONName = parts[0]
+				}
+				for _, opt := range parts[1:] {
+					if opt == "omitempty" {
+						field.OmitEmpty = true
+					}
+				}
+			}
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// ExprString renders a type expression back to Go source form
+func ExprString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return "*" + ExprString(e.X)
+	case *ast.SelectorExpr:
+		return ExprString(e.X) + "." + e.Sel.Name
+	case *ast.ArrayType:
+		return "[]" + ExprString(e.Elt)
+	case *ast.MapType:
+		return "map[" + ExprString(e.Key) + "]" + ExprString(e.Value)
+	case *ast.InterfaceType:
+		return "interface{}"
+	default:
+		return ""
+	}
+}
+
+// receiverName returns the type name of a method receiver
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// returnedString returns the string literal of a `return "..."` body
+func returnedString(body *ast.BlockStmt) string {
+	if body == nil || len(body.List) != 1 {
+		return ""
+	}
+	ret, ok := body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return ""
+	}
+	lit, ok := ret.Results[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	s, _ := strconv.Unquote(lit.Value)
+	return s
+}
+
+// docText returns the first available doc comment
+func docText(groups ...*ast.CommentGroup) string {
+	for _, g := range groups {
+		if g != nil {
+			return strings.TrimSpace(g.Text())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Path is where the manifest is kept, relative to the project root
+const Path = ".went/manifest.json"
+
+// formatVersion is bumped whenever the manifest layout changes
+const formatVersion = 1
+
+// Manifest records every file WentPlate generated in a project
+type Manifest struct {
+	Version int              `json:"version"`
+	Files   map[string]Entry `json:"files"`
+}
+
+// Entry describes how a single file was generated
+type Entry struct {
+	Generator       string    `json:"generator"`        // e.g. "make:controller"
+	Inputs          []string  `json:"inputs,omitempty"` // generator arguments, e.g. ["Admin/User", "--router", "chi"]
+	Template        string    `json:"template"`         // e.g. "controller_gin"
+	TemplateVersion string    `json:"template_version"` // SHA-256 of the template source
+	WentVersion     string    `json:"went_version"`
+	SHA256          string    `json:"sha256"` // SHA-256 of the rendered output
+	GeneratedAt     time.Time `json:"generated_at"`
+}
+
+// State is the condition of a generated file compared with its entry
+type State string
+
+const (
+	Pristine   State = "pristine"   // unchanged since generation
+	Customized State = "customized" // edited after generation
+	Missing    State = "missing"    // deleted after generation
+)
+
+// Load reads the manifest at path; a missing file yields an empty manifest
+func Load(path string) (*Manifest, error) {
+	m := &Manifest{Version: formatVersion, Files: map[string]Entry{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	if m.Files == nil {
+		m.Files = map[string]Entry{}
+	}
+	return m, nil
+}
+
+// Save writes the manifest to path
+func (m *Manifest) Save(path string) error {
+	m.Version = formatVersion
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Paths returns the recorded file paths in sorted order
+func (m *Manifest) Paths() []string {
+	paths := make([]string, 0, len(m.Files))
+	for p := range m.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Check compares the file at path with its recorded hash
+func (e Entry) Check(path string) State {
+	content, err := os.ReadFile(path)
+	switch {
+	case err != nil:
+		return Missing
+	case Hash(content) != e.SHA256:
+		return Customized
+	default:
+		return Pristine
+	}
+}
+
+// Hash returns the hex encoded SHA-256 of content
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,756 @@
+package openapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"went-plate/internal/inflect"
+	"went-plate/internal/inspect"
+)
+
+// Build creates an OpenAPI 3.1 document from parsed models and controllers
+func Build(title string, models []inspect.Model, controllers []inspect.Controller) *Document {
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info:    Info{Title: title, Version: "1.0.0"},
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas:    map[string]*Schema{},
+			Parameters: commonParameters(),
+			Responses:  commonResponses(),
+		},
+	}
+
+	doc.Components.Schemas["Problem"] = &Schema{
+		Type:        Types{"object"},
+		Description: "RFC 7807 problem details",
+		Properties: map[string]*Schema{
+			"type":     {Type: Types{"string"}, Default: "about:blank"},
+			"title":    {Type: Types{"string"}},
+			"status":   {Type: Types{"integer"}},
+			"detail":   {Type: Types{"string"}},
+			"instance": {Type: Types{"string"}},
+			"trace_id": {Type: Types{"string"}},
+			"errors": {
+				Type:                 Types{"object"},
+				Description:          "Validation messages by JSON field, e.g. {\"name\": [\"is required\"]}",
+				AdditionalProperties: &Schema{Type: Types{"array"}, Items: &Schema{Type: Types{"string"}}},
+			},
+		},
+		Required: []string{"type", "title", "status", "trace_id"},
+	}
+	doc.Components.Schemas["Message"] = &Schema{
+		Type:       Types{"object"},
+		Properties: map[string]*Schema{"message": {Type: Types{"string"}}},
+	}
+	doc.Components.Schemas["PatchOperation"] = &Schema{
+		Type:        Types{"object"},
+		Description: "An RFC 6902 JSON Patch operation",
+		Properties: map[string]*Schema{
+			"op":    {Type: Types{"string"}, Enum: []interface{}{"add", "remove", "replace", "move", "copy", "test"}},
+			"path":  {Type: Types{"string"}, Description: "JSON pointer, e.g. /name"},
+			"from":  {Type: Types{"string"}, Description: "Source pointer of move and copy"},
+			"value": {Description: "Value of add, replace and test"},
+		},
+		Required: []string{"op", "path"},
+	}
+	doc.Components.Schemas["BatchResult"] = &Schema{
+		Type:        Types{"object"},
+		Description: "Outcome of a single item of a batch request",
+		Properties: map[string]*Schema{
+			"index":  {Type: Types{"integer"}, Description: "Position of the item in the request"},
+			"status": {Type: Types{"integer"}, Description: "HTTP status the item would have been answered with on its own"},
+			"data":   {Description: "The stored record, on success"},
+			"detail": {Type: Types{"string"}},
+			"errors": {
+				Type:                 Types{"object"},
+				AdditionalProperties: &Schema{Type: Types{"array"}, Items: &Schema{Type: Types{"string"}}},
+			},
+		},
+		Required: []string{"index", "status"},
+	}
+	doc.Components.Schemas["ImportReport"] = &Schema{
+		Type: Types{"object"},
+		Properties: map[string]*Schema{
+			"imported": {Type: Types{"integer"}},
+			"failed":   {Type: Types{"integer"}},
+			"errors": {
+				Type:        Types{"array"},
+				Description: "The lines that failed, up to 1000",
+				Items: &Schema{
+					Type: Types{"object"},
+					Properties: map[string]*Schema{
+						"line":   {Type: Types{"integer"}},
+						"status": {Type: Types{"integer"}},
+						"detail": {Type: Types{"string"}},
+						"errors": {
+							Type:                 Types{"object"},
+							AdditionalProperties: &Schema{Type: Types{"array"}, Items: &Schema{Type: Types{"string"}}},
+						},
+					},
+					Required: []string{"line", "status"},
+				},
+			},
+		},
+		Required: []string{"imported", "failed"},
+	}
+	doc.Components.Schemas["PaginationMeta"] = &Schema{
+		Type: Types{"object"},
+		Properties: map[string]*Schema{
+			"query":        {Type: Types{"string"}},
+			"current_page": {Type: Types{"integer"}},
+			"total_pages":  {Type: Types{"integer"}},
+			"total_count":  {Type: Types{"integer"}, Format: "int64"},
+			"limit":        {Type: Types{"integer"}},
+		},
+	}
+	doc.Components.Schemas["CursorMeta"] = &Schema{
+		Type: Types{"object"},
+		Properties: map[string]*Schema{
+			"next_cursor": {Type: Types{"string"}, Description: "Cursor of the next page, empty on the last one"},
+			"prev_cursor": {Type: Types{"string"}, Description: "Cursor of the previous page, empty on the first one"},
+			"total_count": {Type: Types{"integer"}, Format: "int64", Description: "Only with total=true"},
+			"limit":       {Type: Types{"integer"}},
+		},
+	}
+
+	for _, m := range models {
+		doc.Components.Schemas[schemaName(m.Namespace, m.Name)] = modelSchema(m, schemaScope(models, m.Namespace))
+	}
+
+	for _, c := range controllers {
+		// Controllers use the model of their own namespace, falling back to the root package
+		scope := schemaScope(models, c.Namespace)
+		model := inspect.Model{Name: c.Resource, Namespace: c.Namespace}
+		for _, m := range models {
+			if m.Name == c.Resource && scope[m.Name] == schemaName(m.Namespace, m.Name) {
+				model = m
+			}
+		}
+		resource := schemaName(c.Namespace, c.Resource)
+		base := "/" + inflect.Route(c.Resource)
+		if c.Namespace != "" {
+			base = "/" + c.Namespace + base
+		}
+
+		for _, route := range c.Routes {
+			path := base + strings.TrimSuffix(route.Path, "/")
+			item, ok := doc.Paths[path]
+			if !ok {
+				item = &PathItem{}
+				doc.Paths[path] = item
+			}
+			op := operation(resource, schemaName(model.Namespace, model.Name), model, model.Associations(models), scope, route)
+			switch route.Method {
+			case http.MethodGet:
+				item.Get = op
+			case http.MethodPost:
+				item.Post = op
+			case http.MethodPut:
+				item.Put = op
+			case http.MethodPatch:
+				item.Patch = op
+			case http.MethodDelete:
+				item.Delete = op
+			}
+		}
+	}
+
+	return doc
+}
+
+// operation describes a single controller handler; resource names the
+// controller, schema the component describing its model and scope the
+// components its associations refer to
+func operation(resource, schema string, model inspect.Model, associations []inspect.Association, scope map[string]string, route inspect.Route) *Operation {
+	ref := &Schema{Ref: "#/components/schemas/" + schema}
+	op := &Operation{
+		OperationID: lowerFirst(route.Handler) + resource,
+		Tags:        []string{resource},
+		Responses: map[string]*Response{
+			"403": {Ref: "#/components/responses/Forbidden"},
+			"500": {Ref: "#/components/responses/InternalServerError"},
+		},
+	}
+
+	for _, segment := range strings.Split(route.Path, "/") {
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+		name := strings.Trim(segment, "{}")
+		param := &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: Types{"string"}}}
+		switch name {
+		case "id":
+			param.Schema = idSchema(model)
+		case "field":
+			var fields []interface{}
+			for _, f := range columnFields(model, associations) {
+				fields = append(fields, f)
+			}
+			param.Schema.Enum = fields
+		}
+		op.Parameters = append(op.Parameters, param)
+	}
+	if len(op.Parameters) > 0 {
+		op.Responses["400"] = &Response{Ref: "#/components/responses/BadRequest"}
+	}
+
+	switch route.Handler {
+	case "Index":
+		op.Summary = "List " + resource + " records with pagination and search"
+		if model.Keyset {
+			op.Parameters = append(op.Parameters,
+				&Parameter{Ref: "#/components/parameters/limit"},
+				&Parameter{Ref: "#/components/parameters/search"},
+				&Parameter{Name: "cursor", In: "query", Description: "next_cursor or prev_cursor of a previous page", Schema: &Schema{Type: Types{"string"}}},
+				&Parameter{Name: "total", In: "query", Description: "Include total_count, which costs a COUNT query", Schema: &Schema{Type: Types{"boolean"}, Default: false}},
+			)
+			envelope := listEnvelope(ref)
+			envelope.Properties["meta"] = &Schema{Ref: "#/components/schemas/CursorMeta"}
+			op.Responses["200"] = jsonResponse("Page of "+resource+" records", envelope)
+		} else {
+			op.Parameters = append(op.Parameters,
+				&Parameter{Ref: "#/components/parameters/page"},
+				&Parameter{Ref: "#/components/parameters/limit"},
+				&Parameter{Ref: "#/components/parameters/search"},
+			)
+			op.Responses["200"] = jsonResponse("Paginated "+resource+" list", listEnvelope(ref))
+		}
+		queryParameters(op, model, associations)
+		includeParameter(op, associations)
+	case "Search":
+		op.Summary = "Search " + resource + " records"
+		op.Parameters = append(op.Parameters,
+			&Parameter{Name: "q", In: "query", Required: true, Description: "Search query", Schema: &Schema{Type: Types{"string"}}},
+			&Parameter{Ref: "#/components/parameters/page"},
+			&Parameter{Ref: "#/components/parameters/limit"},
+		)
+		op.Responses["200"] = jsonResponse("Matching "+resource+" records", listEnvelope(ref))
+		op.Responses["400"] = &Response{Ref: "#/components/responses/BadRequest"}
+	case "Show", "GetByField":
+		op.Summary = "Get a single " + resource
+		if route.Handler == "GetByField" {
+			op.Summary = "Find a " + resource + " by field value"
+		}
+		op.Responses["200"] = jsonResponse(resource+" found", dataEnvelope(ref, false))
+		op.Responses["404"] = &Response{Ref: "#/components/responses/NotFound"}
+		if route.Handler == "Show" {
+			includeParameter(op, associations)
+		}
+	case "Store":
+		op.Summary = "Create a " + resource
+		op.RequestBody = jsonBody(ref)
+		op.Responses["201"] = jsonResponse(resource+" created", dataEnvelope(ref, true))
+		op.Responses["400"] = &Response{Ref: "#/components/responses/BadRequest"}
+		op.Responses["409"] = &Response{Ref: "#/components/responses/Conflict"}
+		op.Responses["422"] = &Response{Ref: "#/components/responses/UnprocessableEntity"}
+	case "Update":
+		op.Summary = "Replace a " + resource
+		op.RequestBody = jsonBody(ref)
+		op.Responses["200"] = jsonResponse(resource+" updated", dataEnvelope(ref, true))
+		op.Responses["404"] = &Response{Ref: "#/components/responses/NotFound"}
+		op.Responses["409"] = &Response{Ref: "#/components/responses/Conflict"}
+		op.Responses["422"] = &Response{Ref: "#/components/responses/UnprocessableEntity"}
+	case "Patch":
+		op.Summary = "Partially update a " + resource
+		op.RequestBody = &RequestBody{
+			Description: "Only the fields the patch changes are written; id and timestamps are read-only. A failed test operation answers 409.",
+			Required:    true,
+			Content: map[string]*MediaType{
+				"application/merge-patch+json": {Schema: &Schema{Type: Types{"object"}, Description: "RFC 7396 merge patch: null removes a field", AdditionalProperties: true}},
+				"application/json-patch+json":  {Schema: &Schema{Type: Types{"array"}, Items: &Schema{Ref: "#/components/schemas/PatchOperation"}}},
+			},
+		}
+		op.Responses["200"] = jsonResponse(resource+" patched", dataEnvelope(ref, true))
+		op.Responses["400"] = &Response{Ref: "#/components/responses/BadRequest"}
+		op.Responses["404"] = &Response{Ref: "#/components/responses/NotFound"}
+		op.Responses["409"] = &Response{Ref: "#/components/responses/Conflict"}
+		op.Responses["422"] = &Response{Ref: "#/components/responses/UnprocessableEntity"}
+	case "UpdateOrCreate":
+		op.Summary = "Update a " + resource + " matching the conditions or create it"
+		op.RequestBody = jsonBody(&Schema{
+			Type: Types{"object"},
+			Properties: map[string]*Schema{
+				"conditions": {Type: Types{"object"}, AdditionalProperties: true},
+				"data":       ref,
+			},
+			Required: []string{"conditions", "data"},
+		})
+		op.Responses["200"] = jsonResponse(resource+" upserted", dataEnvelope(ref, true))
+		op.Responses["400"] = &Response{Ref: "#/components/responses/BadRequest"}
+		op.Responses["409"] = &Response{Ref: "#/components/responses/Conflict"}
+		op.Responses["422"] = &Response{Ref: "#/components/responses/UnprocessableEntity"}
+	case "Delete", "SoftDelete", "Restore":
+		op.Summary = map[string]string{
+			"Delete":     "Permanently delete a " + resource,
+			"SoftDelete": "Soft delete a " + resource,
+			"Restore":    "Restore a soft deleted " + resource,
+		}[route.Handler]
+		op.Responses["200"] = jsonResponse(op.Summary, &Schema{Ref: "#/components/schemas/Message"})
+		if route.Handler != "Restore" {
+			op.Responses["404"] = &Response{Ref: "#/components/responses/NotFound"}
+		}
+	case "BatchDelete":
+		op.Summary = "Delete multiple " + resource + " records"
+		op.RequestBody = jsonBody(&Schema{
+			Type: Types{"object"},
+			Properties: map[string]*Schema{
+				"ids":  {Type: Types{"array"}, Items: idSchema(model)},
+				"soft": {Type: Types{"boolean"}},
+			},
+			Required: []string{"ids"},
+		})
+		op.Responses["200"] = jsonResponse(resource+" records deleted", &Schema{
+			Type: Types{"object"},
+			Properties: map[string]*Schema{
+				"message": {Type: Types{"string"}},
+				"count":   {Type: Types{"integer"}},
+			},
+		})
+		op.Responses["400"] = &Response{Ref: "#/components/responses/BadRequest"}
+	case "BatchStore", "BatchUpdate":
+		item := ref
+		op.Summary = "Create multiple " + resource + " records"
+		status := "201"
+		if route.Handler == "BatchUpdate" {
+			op.Summary = "Merge patch multiple " + resource + " records"
+			item = &Schema{Type: Types{"object"}, Description: "RFC 7396 merge patch naming its record with id", AdditionalProperties: true}
+			status = "200"
+		}
+		op.RequestBody = jsonBody(&Schema{
+			Type:       Types{"object"},
+			Properties: map[string]*Schema{"items": {Type: Types{"array"}, Items: item}},
+			Required:   []string{"items"},
+		})
+		results := &Schema{
+			Type:       Types{"object"},
+			Properties: map[string]*Schema{"data": {Type: Types{"array"}, Items: &Schema{Ref: "#/components/schemas/BatchResult"}}},
+		}
+		op.Responses[status] = jsonResponse("Every item succeeded", results)
+		op.Responses["207"] = jsonResponse("Some items failed; see the status of each result", results)
+		op.Responses["400"] = &Response{Ref: "#/components/responses/BadRequest"}
+	case "Export":
+		op.Summary = "Export " + resource + " records as CSV or JSON Lines"
+		op.Parameters = append(op.Parameters,
+			&Parameter{Name: "format", In: "query", Schema: &Schema{Type: Types{"string"}, Enum: []interface{}{"csv", "jsonl"}, Default: "csv"}},
+			&Parameter{Name: "search", In: "query", Description: "Search query", Schema: &Schema{Type: Types{"string"}}},
+		)
+		offset := model
+		offset.Keyset = false // exports sort like offset pagination
+		queryParameters(op, offset, associations)
+		op.Responses["200"] = &Response{
+			Description: "The matching " + resource + " records, streamed as an attachment",
+			Content: map[string]*MediaType{
+				"text/csv":             {Schema: &Schema{Type: Types{"string"}, Description: "A header row of JSON fields, then a row per record"}},
+				"application/x-ndjson": {Schema: &Schema{Type: Types{"string"}, Description: "A JSON object per line"}},
+			},
+		}
+	case "Import":
+		op.Summary = "Import " + resource + " records from CSV or JSON Lines"
+		op.Parameters = append(op.Parameters,
+			&Parameter{Name: "format", In: "query", Description: "Overrides the Content-Type of the body", Schema: &Schema{Type: Types{"string"}, Enum: []interface{}{"csv", "jsonl"}}},
+		)
+		op.RequestBody = &RequestBody{
+			Description: "Rows are validated one by one; valid rows are inserted in batches",
+			Required:    true,
+			Content: map[string]*MediaType{
+				"text/csv":             {Schema: &Schema{Type: Types{"string"}, Description: "A header row of JSON fields, then a row per record"}},
+				"application/x-ndjson": {Schema: &Schema{Type: Types{"string"}, Description: "A JSON object per line"}},
+			},
+		}
+		report := &Schema{
+			Type:       Types{"object"},
+			Properties: map[string]*Schema{"data": {Ref: "#/components/schemas/ImportReport"}},
+		}
+		op.Responses["201"] = jsonResponse("Every line was imported", report)
+		op.Responses["207"] = jsonResponse("Some lines failed; see the report", report)
+		op.Responses["400"] = &Response{Ref: "#/components/responses/BadRequest"}
+	default:
+		op.Summary = route.Handler
+		op.Responses["200"] = &Response{Description: "OK"}
+		for _, a := range associations {
+			if a.Kind != "has-many" || route.Handler != "List"+a.Field {
+				continue
+			}
+			op.Summary = "List the " + a.Field + " of a " + resource
+			op.Parameters = append(op.Parameters,
+				&Parameter{Ref: "#/components/parameters/page"},
+				&Parameter{Ref: "#/components/parameters/limit"},
+			)
+			item := &Schema{Type: Types{"object"}}
+			if name, ok := scope[a.Model]; ok {
+				item = &Schema{Ref: "#/components/schemas/" + name}
+			}
+			op.Responses["200"] = jsonResponse("Paginated "+a.Field+" list", listEnvelope(item))
+			op.Responses["404"] = &Response{Ref: "#/components/responses/NotFound"}
+		}
+	}
+	if model.Versioned {
+		versionHeaders(op, route.Handler)
+	}
+
+	return op
+}
+
+// versionHeaders documents the ETags of versioned models: reads and writes
+// return the ETag of the record, reads honour If-None-Match and changes
+// If-Match
+func versionHeaders(op *Operation, handler string) {
+	tag := &Schema{Type: Types{"string"}}
+	etag := map[string]*Header{"ETag": {Description: "Version of the record", Schema: tag}}
+	switch handler {
+	case "Show", "GetByField":
+		op.Parameters = append(op.Parameters, &Parameter{Name: "If-None-Match", In: "header", Description: "ETag of a cached copy, answered with 304 while it is current", Schema: tag})
+		op.Responses["200"].Headers = etag
+		op.Responses["304"] = &Response{Description: "The cached copy is current"}
+	case "Update", "Patch", "Delete", "SoftDelete":
+		op.Parameters = append(op.Parameters, &Parameter{Name: "If-Match", In: "header", Description: "ETag the change is based on, answered with 412 once the record has changed", Schema: tag})
+		op.Responses["412"] = &Response{Ref: "#/components/responses/PreconditionFailed"}
+		if handler == "Update" || handler == "Patch" {
+			op.Responses["200"].Headers = etag
+		}
+	case "Store":
+		op.Responses["201"].Headers = etag
+	case "UpdateOrCreate":
+		op.Responses["200"].Headers = etag
+	}
+}
+
+// includeParameter documents the ?include= parameter of handlers preloading
+// the model's associations
+func includeParameter(op *Operation, associations []inspect.Association) {
+	var names []string
+	for _, a := range associations {
+		if a.JSONName != "" {
+			names = append(names, a.JSONName)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	op.Parameters = append(op.Parameters, &Parameter{
+		Name:        "include",
+		In:          "query",
+		Description: "Comma separated associations to load: " + strings.Join(names, ", "),
+		Schema:      &Schema{Type: Types{"string"}},
+	})
+	op.Responses["400"] = &Response{Ref: "#/components/responses/BadRequest"}
+}
+
+// queryParameters documents the sort and filter parameters of a list
+// operation, which accept the model's own columns only
+func queryParameters(op *Operation, model inspect.Model, associations []inspect.Association) {
+	fields := columnFields(model, associations)
+	sort := "Comma separated fields, prefixed with - for descending order, e.g. \"-created_at,name\". Fields: "
+	if model.Keyset {
+		sort = "A single field, prefixed with - for descending order, e.g. \"-created_at\". Fields: "
+	}
+	explode := true
+	op.Parameters = append(op.Parameters,
+		&Parameter{
+			Name:        "sort",
+			In:          "query",
+			Description: sort + strings.Join(fields, ", "),
+			Schema:      &Schema{Type: Types{"string"}, Default: "-created_at"},
+		},
+		&Parameter{
+			Name:        "filter",
+			In:          "query",
+			Description: "Field filters: filter[field]=value, or filter[field][op]=value with op one of eq, ne, gt, gte, lt, lte, in (comma separated values), like. Fields: " + strings.Join(fields, ", "),
+			Style:       "deepObject",
+			Explode:     &explode,
+			Schema:      &Schema{Type: Types{"object"}, AdditionalProperties: &Schema{}},
+		},
+	)
+	op.Responses["400"] = &Response{Ref: "#/components/responses/BadRequest"}
+}
+
+// columnFields returns the fields of the generated column whitelist: the id,
+// the model's own fields and the timestamps
+func columnFields(model inspect.Model, associations []inspect.Association) []string {
+	skip := map[string]bool{"ID": true, "CreatedAt": true, "UpdatedAt": true, "DeletedAt": true}
+	for _, a := range associations {
+		skip[a.Field] = true
+	}
+	fields := []string{"id"}
+	for _, f := range model.Fields {
+		if f.JSONName != "" && !f.Embedded && !skip[f.Name] {
+			fields = append(fields, f.JSONName)
+		}
+	}
+	return append(fields, "created_at", "updated_at")
+}
+
+// schemaName returns the component name of a model, prefixed with its
+// namespace ("admin", "User" -> "AdminUser")
+func schemaName(namespace, name string) string {
+	return inflect.Pascal(namespace) + name
+}
+
+// schemaScope maps the Go type names visible from a namespace to their
+// component names: models of the namespace itself shadow root models
+func schemaScope(models []inspect.Model, namespace string) map[string]string {
+	scope := map[string]string{}
+	for _, m := range models {
+		if m.Namespace == "" {
+			scope[m.Name] = m.Name
+		}
+	}
+	for _, m := range models {
+		if namespace != "" && m.Namespace == namespace {
+			scope[m.Name] = schemaName(m.Namespace, m.Name)
+		}
+	}
+	return scope
+}
+
+// modelSchema converts a parsed model into an object schema
+func modelSchema(m inspect.Model, models map[string]string) *Schema {
+	schema := &Schema{Type: Types{"object"}, Description: m.Doc, Properties: map[string]*Schema{}}
+	var embedded []*Schema
+
+	for _, f := range m.Fields {
+		if f.Embedded {
+			switch f.Type {
+			case "gorm.Model":
+				schema.Properties["ID"] = &Schema{Type: Types{"integer"}, ReadOnly: true}
+				schema.Properties["CreatedAt"] = &Schema{Type: Types{"string"}, Format: "date-time", ReadOnly: true}
+				schema.Properties["UpdatedAt"] = &Schema{Type: Types{"string"}, Format: "date-time", ReadOnly: true}
+				schema.Properties["DeletedAt"] = &Schema{Type: Types{"string", "null"}, Format: "date-time", ReadOnly: true}
+			default:
+				if name, ok := models[strings.TrimPrefix(f.Type, "*")]; ok {
+					embedded = append(embedded, &Schema{Ref: "#/components/schemas/" + name})
+				}
+			}
+			continue
+		}
+		if f.JSONName == "" {
+			continue
+		}
+
+		prop := typeSchema(f.Type, models)
+		if isReadOnly(f) {
+			prop.ReadOnly = true
+		}
+		if applyValidation(prop, f.Validate) {
+			schema.Required = append(schema.Required, f.JSONName)
+		}
+		schema.Properties[f.JSONName] = prop
+	}
+
+	if len(embedded) > 0 {
+		return &Schema{AllOf: append(embedded, schema)}
+	}
+	return schema
+}
+
+// typeSchema maps a Go type expression to a schema
+func typeSchema(goType string, models map[string]string) *Schema {
+	switch {
+	case strings.HasPrefix(goType, "*"):
+		s := typeSchema(goType[1:], models)
+		if s.Ref != "" {
+			return s
+		}
+		s.Type = append(s.Type, "null")
+		return s
+	case goType == "[]byte":
+		return &Schema{Type: Types{"string"}, Format: "byte"}
+	case strings.HasPrefix(goType, "[]"):
+		return &Schema{Type: Types{"array"}, Items: typeSchema(goType[2:], models)}
+	case strings.HasPrefix(goType, "map["):
+		return &Schema{Type: Types{"object"}, AdditionalProperties: true}
+	}
+
+	switch goType {
+	case "string":
+		return &Schema{Type: Types{"string"}}
+	case "bool":
+		return &Schema{Type: Types{"boolean"}}
+	case "int", "int64", "uint", "uint64":
+		return &Schema{Type: Types{"integer"}, Format: "int64"}
+	case "int8", "int16", "int32", "uint8", "uint16", "uint32":
+		return &Schema{Type: Types{"integer"}, Format: "int32"}
+	case "float32":
+		return &Schema{Type: Types{"number"}, Format: "float"}
+	case "float64":
+		return &Schema{Type: Types{"number"}, Format: "double"}
+	case "time.Time":
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case "gorm.DeletedAt", "sql.NullTime":
+		return &Schema{Type: Types{"string", "null"}, Format: "date-time"}
+	case "uuid.UUID":
+		return &Schema{Type: Types{"string"}, Format: "uuid"}
+	case "interface{}", "any", "json.RawMessage", "datatypes.JSON":
+		return &Schema{}
+	}
+
+	if name, ok := models[goType]; ok {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+// applyValidation maps go-playground/validator rules to schema constraints
+// and reports whether the field is required
+func applyValidation(s *Schema, rules string) bool {
+	required := false
+	isString := s.Type.Is("string")
+	isArray := s.Type.Is("array")
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "url", "uri":
+			s.Format = "uri"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "datetime":
+			s.Format = "date-time"
+		case "oneof":
+			for _, v := range strings.Fields(arg) {
+				s.Enum = append(s.Enum, v)
+			}
+		case "min", "max", "len", "gte", "lte", "gt", "lt":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			switch {
+			case isString:
+				setLength(&s.MinLength, &s.MaxLength, name, int(n))
+			case isArray:
+				setLength(&s.MinItems, &s.MaxItems, name, int(n))
+			default:
+				switch name {
+				case "min", "gte", "gt":
+					s.Minimum = &n
+				case "max", "lte", "lt":
+					s.Maximum = &n
+				case "len":
+					s.Minimum, s.Maximum = &n, &n
+				}
+			}
+		}
+	}
+	return required
+}
+
+// setLength applies a length rule to a min/max pair
+func setLength(min, max **int, rule string, n int) {
+	switch rule {
+	case "min", "gte":
+		*min = &n
+	case "gt":
+		n++
+		*min = &n
+	case "max", "lte":
+		*max = &n
+	case "lt":
+		n--
+		*max = &n
+	case "len":
+		*min, *max = &n, &n
+	}
+}
+
+// isReadOnly reports whether the field is managed by the database
+func isReadOnly(f inspect.Field) bool {
+	if f.Type == "gorm.DeletedAt" {
+		return true
+	}
+	for _, opt := range strings.FieldsFunc(f.Gorm, func(r rune) bool { return r == ';' || r == ',' }) {
+		switch opt {
+		case "primaryKey", "autoIncrement", "autoCreateTime", "autoUpdateTime":
+			return true
+		}
+	}
+	return false
+}
+
+// idSchema returns the schema of the model's primary key
+func idSchema(m inspect.Model) *Schema {
+	for _, f := range m.Fields {
+		if f.Name == "ID" {
+			s := typeSchema(f.Type, nil)
+			s.ReadOnly = false
+			return s
+		}
+	}
+	return &Schema{Type: Types{"integer"}, Format: "int64"}
+}
+
+// commonParameters returns the shared pagination parameters
+func commonParameters() map[string]*Parameter {
+	one, ten := 1.0, 10.0
+	return map[string]*Parameter{
+		"page": {Name: "page", In: "query", Description: "Page number",
+			Schema: &Schema{Type: Types{"integer"}, Minimum: &one, Default: 1}},
+		"limit": {Name: "limit", In: "query", Description: "Records per page",
+			Schema: &Schema{Type: Types{"integer"}, Minimum: &one, Default: int(ten)}},
+		"search": {Name: "search", In: "query", Description: "Full text search on name and description",
+			Schema: &Schema{Type: Types{"string"}}},
+	}
+}
+
+// commonResponses returns the shared error responses, problem+json bodies
+// written by the generated httperr package
+func commonResponses() map[string]*Response {
+	errorResponse := func(description string) *Response {
+		return &Response{Description: description, Content: map[string]*MediaType{
+			"application/problem+json": {Schema: &Schema{Ref: "#/components/schemas/Problem"}},
+		}}
+	}
+	return map[string]*Response{
+		"BadRequest":          errorResponse("Malformed request"),
+		"Forbidden":           errorResponse("Denied by the resource policy"),
+		"NotFound":            errorResponse("Record not found"),
+		"Conflict":            errorResponse("Unique or foreign key constraint violated"),
+		"UnprocessableEntity": errorResponse("Validation failed"),
+		"PreconditionFailed":  errorResponse("If-Match names an outdated version of the record"),
+		"InternalServerError": errorResponse("Unexpected error, logged with the trace id"),
+	}
+}
+
+// listEnvelope wraps an item schema in the paginated response envelope
+func listEnvelope(item *Schema) *Schema {
+	return &Schema{
+		Type: Types{"object"},
+		Properties: map[string]*Schema{
+			"data": {Type: Types{"array"}, Items: item},
+			"meta": {Ref: "#/components/schemas/PaginationMeta"},
+		},
+	}
+}
+
+// dataEnvelope wraps a schema in the {"data": ...} response envelope
+func dataEnvelope(item *Schema, withMessage bool) *Schema {
+	s := &Schema{Type: Types{"object"}, Properties: map[string]*Schema{"data": item}}
+	if withMessage {
+		s.Properties["message"] = &Schema{Type: Types{"string"}}
+	}
+	return s
+}
+
+// jsonBody builds a required application/json request body
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: schema}}}
+}
+
+// jsonResponse builds an application/json response
+func jsonResponse(description string, schema *Schema) *Response {
+	return &Response{Description: description, Content: map[string]*MediaType{"application/json": {Schema: schema}}}
+}
+
+// lowerFirst lowercases the first letter of a string
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,183 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is the subset of an OpenAPI 3.x document WentPlate reads and writes
+type Document struct {
+	OpenAPI    string               `yaml:"openapi" json:"openapi"`
+	Info       Info                 `yaml:"info" json:"info"`
+	Servers    []Server             `yaml:"servers,omitempty" json:"servers,omitempty"`
+	Paths      map[string]*PathItem `yaml:"paths" json:"paths"`
+	Components Components           `yaml:"components,omitempty" json:"components,omitempty"`
+}
+
+// Info holds the API metadata
+type Info struct {
+	Title       string `yaml:"title" json:"title"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Version     string `yaml:"version" json:"version"`
+}
+
+// Server is an API base URL
+type Server struct {
+	URL string `yaml:"url" json:"url"`
+}
+
+// PathItem holds the operations available on a single path
+type PathItem struct {
+	Get    *Operation `yaml:"get,omitempty" json:"get,omitempty"`
+	Put    *Operation `yaml:"put,omitempty" json:"put,omitempty"`
+	Post   *Operation `yaml:"post,omitempty" json:"post,omitempty"`
+	Delete *Operation `yaml:"delete,omitempty" json:"delete,omitempty"`
+	Patch  *Operation `yaml:"patch,omitempty" json:"patch,omitempty"`
+
+	Parameters []*Parameter `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+}
+
+// Operation describes a single API operation on a path
+type Operation struct {
+	OperationID string               `yaml:"operationId,omitempty" json:"operationId,omitempty"`
+	Summary     string               `yaml:"summary,omitempty" json:"summary,omitempty"`
+	Tags        []string             `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Parameters  []*Parameter         `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	RequestBody *RequestBody         `yaml:"requestBody,omitempty" json:"requestBody,omitempty"`
+	Responses   map[string]*Response `yaml:"responses" json:"responses"`
+}
+
+// Parameter describes a path or query parameter
+type Parameter struct {
+	Ref         string  `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Name        string  `yaml:"name,omitempty" json:"name,omitempty"`
+	In          string  `yaml:"in,omitempty" json:"in,omitempty"`
+	Description string  `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool    `yaml:"required,omitempty" json:"required,omitempty"`
+	Style       string  `yaml:"style,omitempty" json:"style,omitempty"`
+	Explode     *bool   `yaml:"explode,omitempty" json:"explode,omitempty"`
+	Schema      *Schema `yaml:"schema,omitempty" json:"schema,omitempty"`
+}
+
+// RequestBody describes an operation's request payload
+type RequestBody struct {
+	Ref         string                `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Description string                `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool                  `yaml:"required,omitempty" json:"required,omitempty"`
+	Content     map[string]*MediaType `yaml:"content,omitempty" json:"content,omitempty"`
+}
+
+// Response describes a single response of an operation
+type Response struct {
+	Ref         string                `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Description string                `yaml:"description,omitempty" json:"description,omitempty"`
+	Headers     map[string]*Header    `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Content     map[string]*MediaType `yaml:"content,omitempty" json:"content,omitempty"`
+}
+
+// Header describes a response header
+type Header struct {
+	Description string  `yaml:"description,omitempty" json:"description,omitempty"`
+	Schema      *Schema `yaml:"schema,omitempty" json:"schema,omitempty"`
+}
+
+// MediaType wraps the schema of a request or response body
+type MediaType struct {
+	Schema *Schema `yaml:"schema,omitempty" json:"schema,omitempty"`
+}
+
+// Components holds reusable definitions
+type Components struct {
+	Schemas    map[string]*Schema    `yaml:"schemas,omitempty" json:"schemas,omitempty"`
+	Parameters map[string]*Parameter `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	Responses  map[string]*Response  `yaml:"responses,omitempty" json:"responses,omitempty"`
+}
+
+// Schema is a JSON Schema (OpenAPI 3.1 dialect, with 3.0 "nullable" accepted)
+type Schema struct {
+	Ref                  string             `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Type                 Types              `yaml:"type,omitempty" json:"type,omitempty"`
+	Format               string             `yaml:"format,omitempty" json:"format,omitempty"`
+	Description          string             `yaml:"description,omitempty" json:"description,omitempty"`
+	Properties           map[string]*Schema `yaml:"properties,omitempty" json:"properties,omitempty"`
+	Required             []string           `yaml:"required,omitempty" json:"required,omitempty"`
+	Items                *Schema            `yaml:"items,omitempty" json:"items,omitempty"`
+	AdditionalProperties interface{}        `yaml:"additionalProperties,omitempty" json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `yaml:"enum,omitempty" json:"enum,omitempty"`
+	Default              interface{}        `yaml:"default,omitempty" json:"default,omitempty"`
+	Minimum              *float64           `yaml:"minimum,omitempty" json:"minimum,omitempty"`
+	Maximum              *float64           `yaml:"maximum,omitempty" json:"maximum,omitempty"`
+	MinLength            *int               `yaml:"minLength,omitempty" json:"minLength,omitempty"`
+	MaxLength            *int               `yaml:"maxLength,omitempty" json:"maxLength,omitempty"`
+	MinItems             *int               `yaml:"minItems,omitempty" json:"minItems,omitempty"`
+	MaxItems             *int               `yaml:"maxItems,omitempty" json:"maxItems,omitempty"`
+	Pattern              string             `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	ReadOnly             bool               `yaml:"readOnly,omitempty" json:"readOnly,omitempty"`
+	Nullable             bool               `yaml:"nullable,omitempty" json:"nullable,omitempty"`
+	AllOf                []*Schema          `yaml:"allOf,omitempty" json:"allOf,omitempty"`
+}
+
+// Types is the JSON Schema "type" keyword, which OpenAPI 3.1 allows to be
+// either a single type name or a list such as [string, "null"]
+type Types []string
+
+// MarshalYAML writes a single type as a plain scalar
+func (t Types) MarshalYAML() (interface{}, error) {
+	if len(t) == 1 {
+		return t[0], nil
+	}
+	return []string(t), nil
+}
+
+// UnmarshalYAML accepts both the scalar and the list form
+func (t *Types) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*t = Types{node.Value}
+		return nil
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		*t = list
+		return nil
+	}
+	return fmt.Errorf("line %d: invalid schema type", node.Line)
+}
+
+// Is reports whether the schema allows the given type
+func (t Types) Is(name string) bool {
+	for _, v := range t {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Primary returns the first non-null type
+func (t Types) Primary() string {
+	for _, v := range t {
+		if v != "null" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Marshal encodes the document as YAML
+func (d *Document) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(d); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
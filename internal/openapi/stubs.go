@@ -0,0 +1,469 @@
+package openapi
+
+import (
+	"fmt"
+	"go/token"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"went-plate/internal/inflect"
+)
+
+// StubPlan is everything make:from-openapi generates from a document
+type StubPlan struct {
+	Models      []StructDef
+	Requests    []StructDef
+	Controllers []ControllerDef
+}
+
+// StructDef is a Go struct generated from a schema
+type StructDef struct {
+	Name       string
+	Doc        string
+	Fields     []FieldDef
+	Imports    []string
+	UsesModels bool // whether a field refers to the models package
+}
+
+// FieldDef is a single struct field
+type FieldDef struct {
+	Name     string
+	Type     string
+	JSONName string
+	Validate string
+}
+
+// ControllerDef groups the operations sharing a tag into one controller
+type ControllerDef struct {
+	Name       string // e.g. "Pets" -> PetsController
+	VarName    string // e.g. "petsController"
+	Operations []OperationDef
+}
+
+// OperationDef describes a single handler
+type OperationDef struct {
+	Handler     string
+	Method      string // GET, POST, ...
+	ChiMethod   string // Get, Post, ...
+	Path        string // OpenAPI and Chi form: /pets/{petId}
+	GinPath     string // Gin form: /pets/:petId
+	Summary     string
+	Status      int
+	PathParams  []ParamDef
+	RequestType string // e.g. "requests.CreatePetRequest", empty when the operation has no body
+	Validates   bool   // whether RequestType has a Validate method
+}
+
+// ParamDef is a path parameter
+type ParamDef struct {
+	Name    string // as written in the path
+	VarName string // Go identifier
+}
+
+// Load reads an OpenAPI 3 document in YAML or JSON form
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("%s is not an OpenAPI 3 document (openapi: %q)", path, doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// Plan converts a document into the structs and handlers to generate
+func Plan(doc *Document) *StubPlan {
+	plan := &StubPlan{}
+	schemas := doc.Components.Schemas
+
+	for _, name := range sortedKeys(schemas) {
+		schema := resolve(schemas, schemas[name])
+		if !schema.Type.Is("object") && len(schema.Properties) == 0 && len(schema.AllOf) == 0 {
+			continue
+		}
+		def := structFromSchema(GoName(name), schema, schemas, "")
+		def.Doc = oneLine(schema.Description)
+		plan.Models = append(plan.Models, def)
+	}
+
+	groups := map[string]*ControllerDef{}
+	for _, path := range sortedKeys(doc.Paths) {
+		item := doc.Paths[path]
+		for _, entry := range []struct {
+			method string
+			op     *Operation
+		}{
+			{http.MethodGet, item.Get},
+			{http.MethodPost, item.Post},
+			{http.MethodPut, item.Put},
+			{http.MethodPatch, item.Patch},
+			{http.MethodDelete, item.Delete},
+		} {
+			if entry.op == nil {
+				continue
+			}
+			op := entry.op
+
+			group := "Default"
+			if len(op.Tags) > 0 {
+				group = GoName(op.Tags[0])
+			} else if segment := firstSegment(path); segment != "" {
+				group = GoName(segment)
+			}
+			c, ok := groups[group]
+			if !ok {
+				c = &ControllerDef{Name: group, VarName: lowerFirstIdent(group) + "Controller"}
+				groups[group] = c
+			}
+
+			def := OperationDef{
+				Handler:   handlerName(op, entry.method, path),
+				Method:    entry.method,
+				ChiMethod: entry.method[:1] + strings.ToLower(entry.method[1:]),
+				Path:      path,
+				Summary:   oneLine(op.Summary),
+				Status:    successStatus(op),
+			}
+			segments := strings.Split(path, "/")
+			for i, segment := range segments {
+				if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+					name := strings.Trim(segment, "{}")
+					def.PathParams = append(def.PathParams, ParamDef{Name: name, VarName: lowerFirstIdent(GoName(name))})
+					segments[i] = ":" + name
+				}
+			}
+			def.GinPath = strings.Join(segments, "/")
+			def.Handler = uniqueHandler(c, def.Handler)
+
+			if body := requestSchema(op); body != nil {
+				resolved := resolve(schemas, body)
+				if resolved.Type.Is("object") || len(resolved.Properties) > 0 || len(resolved.AllOf) > 0 {
+					req := structFromSchema(def.Handler+"Request", resolved, schemas, "models.")
+					req.Doc = def.Handler + "Request is the request body of " + entry.method + " " + path
+					plan.Requests = append(plan.Requests, req)
+					def.RequestType = "requests." + req.Name
+					def.Validates = true
+				} else {
+					def.RequestType = goType(body, schemas, "models.", true)
+				}
+			}
+			c.Operations = append(c.Operations, def)
+		}
+	}
+
+	for _, name := range sortedKeys(groups) {
+		plan.Controllers = append(plan.Controllers, *groups[name])
+	}
+	return plan
+}
+
+// structFromSchema builds a struct definition from an object schema
+func structFromSchema(name string, schema *Schema, schemas map[string]*Schema, qualifier string) StructDef {
+	def := StructDef{Name: name}
+	props, required := flatten(schema, schemas)
+	imports := map[string]bool{}
+
+	for _, prop := range sortedKeys(props) {
+		ps := props[prop]
+		field := FieldDef{
+			Name:     GoName(prop),
+			JSONName: prop,
+			Type:     goType(ps, schemas, qualifier, required[prop]),
+			Validate: validateTag(resolve(schemas, ps), required[prop]),
+		}
+		if strings.Contains(field.Type, "time.Time") {
+			imports["time"] = true
+		}
+		if strings.Contains(field.Type, "models.") {
+			def.UsesModels = true
+		}
+		def.Fields = append(def.Fields, field)
+	}
+	for imp := range imports {
+		def.Imports = append(def.Imports, imp)
+	}
+	sort.Strings(def.Imports)
+	return def
+}
+
+// flatten merges allOf members into a single property set
+func flatten(schema *Schema, schemas map[string]*Schema) (map[string]*Schema, map[string]bool) {
+	props := map[string]*Schema{}
+	required := map[string]bool{}
+
+	for _, part := range schema.AllOf {
+		p, r := flatten(resolve(schemas, part), schemas)
+		for k, v := range p {
+			props[k] = v
+		}
+		for k := range r {
+			required[k] = true
+		}
+	}
+	for k, v := range schema.Properties {
+		props[k] = v
+	}
+	for _, k := range schema.Required {
+		required[k] = true
+	}
+	return props, required
+}
+
+// goType maps a schema to a Go type expression
+func goType(s *Schema, schemas map[string]*Schema, qualifier string, required bool) string {
+	if s == nil {
+		return "interface{}"
+	}
+	if s.Ref != "" {
+		name := refName(s.Ref)
+		target := resolve(schemas, s)
+		if target.Type.Is("object") || len(target.Properties) > 0 || len(target.AllOf) > 0 {
+			if required {
+				return qualifier + GoName(name)
+			}
+			return "*" + qualifier + GoName(name)
+		}
+		return goType(target, schemas, qualifier, required)
+	}
+
+	var base string
+	switch s.Type.Primary() {
+	case "string":
+		switch s.Format {
+		case "date-time", "date":
+			base = "time.Time"
+		case "byte", "binary":
+			return "[]byte"
+		default:
+			base = "string"
+		}
+	case "integer":
+		base = "int64"
+		if s.Format == "int32" {
+			base = "int32"
+		}
+	case "number":
+		base = "float64"
+		if s.Format == "float" {
+			base = "float32"
+		}
+	case "boolean":
+		base = "bool"
+	case "array":
+		return "[]" + goType(s.Items, schemas, qualifier, true)
+	case "object":
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+
+	if s.Nullable || s.Type.Is("null") {
+		return "*" + base
+	}
+	return base
+}
+
+// validateTag derives go-playground/validator rules from schema constraints
+func validateTag(s *Schema, required bool) string {
+	var rules []string
+	primary := s.Type.Primary()
+
+	if required && primary != "boolean" {
+		rules = append(rules, "required")
+	}
+
+	switch s.Format {
+	case "email":
+		rules = append(rules, "email")
+	case "uuid":
+		rules = append(rules, "uuid")
+	case "uri", "url":
+		rules = append(rules, "url")
+	}
+
+	number := func(f *float64) string { return strconv.FormatFloat(*f, 'f', -1, 64) }
+	switch primary {
+	case "string":
+		if s.MinLength != nil {
+			rules = append(rules, "min="+strconv.Itoa(*s.MinLength))
+		}
+		if s.MaxLength != nil {
+			rules = append(rules, "max="+strconv.Itoa(*s.MaxLength))
+		}
+	case "integer", "number":
+		if s.Minimum != nil {
+			rules = append(rules, "gte="+number(s.Minimum))
+		}
+		if s.Maximum != nil {
+			rules = append(rules, "lte="+number(s.Maximum))
+		}
+	case "array":
+		if s.MinItems != nil {
+			rules = append(rules, "min="+strconv.Itoa(*s.MinItems))
+		}
+		if s.MaxItems != nil {
+			rules = append(rules, "max="+strconv.Itoa(*s.MaxItems))
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		var values []string
+		for _, v := range s.Enum {
+			if v != nil {
+				values = append(values, fmt.Sprint(v))
+			}
+		}
+		rules = append(rules, "oneof="+strings.Join(values, " "))
+	}
+
+	if len(rules) > 0 && !required {
+		rules = append([]string{"omitempty"}, rules...)
+	}
+	return strings.Join(rules, ",")
+}
+
+// requestSchema returns the JSON schema of an operation's request body
+func requestSchema(op *Operation) *Schema {
+	if op.RequestBody == nil {
+		return nil
+	}
+	for _, mediaType := range []string{"application/json", "application/merge-patch+json", "*/*"} {
+		if mt, ok := op.RequestBody.Content[mediaType]; ok && mt.Schema != nil {
+			return mt.Schema
+		}
+	}
+	return nil
+}
+
+// successStatus returns the first 2xx status declared by an operation
+func successStatus(op *Operation) int {
+	codes := sortedKeys(op.Responses)
+	for _, code := range codes {
+		if n, err := strconv.Atoi(code); err == nil && n >= 200 && n < 300 {
+			return n
+		}
+	}
+	return http.StatusOK
+}
+
+// handlerName derives a Go method name from the operationId or method and path
+func handlerName(op *Operation, method, path string) string {
+	if op.OperationID != "" {
+		return GoName(op.OperationID)
+	}
+	name := GoName(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") {
+			name += "By" + GoName(strings.Trim(segment, "{}"))
+		} else if segment != "" {
+			name += GoName(segment)
+		}
+	}
+	return name
+}
+
+// uniqueHandler avoids duplicate method names inside a controller
+func uniqueHandler(c *ControllerDef, name string) string {
+	candidate := name
+	for i := 2; ; i++ {
+		taken := false
+		for _, op := range c.Operations {
+			if op.Handler == candidate {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return candidate
+		}
+		candidate = name + strconv.Itoa(i)
+	}
+}
+
+// resolve follows local $ref pointers to component schemas
+func resolve(schemas map[string]*Schema, s *Schema) *Schema {
+	for i := 0; s != nil && s.Ref != "" && i < 32; i++ {
+		target, ok := schemas[refName(s.Ref)]
+		if !ok {
+			return &Schema{}
+		}
+		s = target
+	}
+	if s == nil {
+		return &Schema{}
+	}
+	return s
+}
+
+// oneLine collapses text onto a single line for use in // comments
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// refName returns the last segment of a $ref pointer
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// firstSegment returns the first static path segment
+func firstSegment(path string) string {
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" && !strings.HasPrefix(segment, "{") {
+			return segment
+		}
+	}
+	return ""
+}
+
+// GoName converts an arbitrary name (snake_case, kebab-case, camelCase) into
+// an exported Go identifier
+func GoName(s string) string {
+	name := inflect.Pascal(s)
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+	return name
+}
+
+// lowerFirstIdent lowercases the leading word of an identifier
+// ("PetID" -> "petID", "URLPath" -> "urlPath")
+func lowerFirstIdent(s string) string {
+	upper := 0
+	for upper < len(s) && unicode.IsUpper(rune(s[upper])) {
+		upper++
+	}
+	switch {
+	case upper == len(s):
+		s = strings.ToLower(s)
+	case upper > 1:
+		s = strings.ToLower(s[:upper-1]) + s[upper-1:]
+	default:
+		s = strings.ToLower(s[:1]) + s[1:]
+	}
+	if token.IsKeyword(s) {
+		s += "Param"
+	}
+	return s
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
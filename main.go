@@ -9,15 +9,26 @@ import (
 )
 
 func main() {
+	// Global flags may appear anywhere on the command line
+	os.Args = extractGlobalFlags(os.Args)
+
+	// os.Exit skips deferred calls, so the summary is printed before it
+	code := run()
+	commands.PrintDryRunSummary()
+	os.Exit(code)
+}
+
+// run dispatches the command and returns the exit code
+func run() int {
 	// Display banner
 	commands.PrintBanner()
 
 	// Handle no arguments - default to new project creation
 	if len(os.Args) < 2 {
 		if err := commands.NewProject("", "", "", ""); err != nil {
-			os.Exit(1)
+			return 1
 		}
-		return
+		return 0
 	}
 
 	// Get the command
@@ -27,38 +38,70 @@ func main() {
 	switch {
 	case command == "version":
 		commands.VersionCommand()
-		return
+		return 0
 
 	case command == "help" || command == "--help" || command == "-h":
 		commands.ShowHelp()
-		return
+		return 0
 
 	case command == "new":
-		handleNewCommand()
-		return
+		return handleNewCommand()
 
 	case strings.HasPrefix(command, "make:"):
 		commands.MakeCommands()
-		return
+		return 0
+
+	case command == "status":
+		commands.StatusCommand()
+		return 0
+
+	case command == "verify":
+		commands.VerifyCommand()
+		return 0
+
+	case strings.HasPrefix(command, "destroy:"):
+		commands.DestroyCommands()
+		return 0
+
+	case strings.HasPrefix(command, "templates:"):
+		commands.TemplateCommands()
+		return 0
+
+	case strings.HasPrefix(command, "openapi:"):
+		commands.OpenAPICommands()
+		return 0
 
 	case strings.HasPrefix(command, "pkg:"):
 		commands.PackageCommands()
-		return
+		return 0
 
 	case strings.HasPrefix(command, "-"):
 		// Handle legacy flag-based usage
-		handleLegacyFlags()
-		return
+		return handleLegacyFlags()
 
 	default:
 		fmt.Printf("\033[31mBilinmeyen komut: %s\033[0m\n\n", command)
 		commands.ShowHelp()
-		os.Exit(2)
+		return 2
+	}
+}
+
+// extractGlobalFlags applies and removes the flags shared by every command
+func extractGlobalFlags(args []string) []string {
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--dry-run" || arg == "-dry-run" {
+			commands.DryRun = true
+			continue
+		}
+		rest = append(rest, arg)
 	}
+	return rest
 }
 
-// handleNewCommand handles the 'new' command with optional flags
-func handleNewCommand() {
+// handleNewCommand handles the 'new' command with optional flags and returns
+// the exit code
+func handleNewCommand() int {
 	// Create a new flag set for the new command
 	newCmd := flag.NewFlagSet("new", flag.ExitOnError)
 
@@ -82,16 +125,18 @@ func handleNewCommand() {
 
 	if *help {
 		commands.ShowHelp()
-		return
+		return 0
 	}
 
 	if err := commands.NewProject(*name, *template, *deployment, *router); err != nil {
-		os.Exit(1)
+		return 1
 	}
+	return 0
 }
 
-// handleLegacyFlags handles the old flag-based interface for backward compatibility
-func handleLegacyFlags() {
+// handleLegacyFlags handles the old flag-based interface for backward
+// compatibility and returns the exit code
+func handleLegacyFlags() int {
 	// Validate flags
 	validFlags := []string{"-N", "--name", "-T", "--template", "-D", "--deployment", "-R", "--router", "-h", "--help"}
 	for _, arg := range os.Args[1:] {
@@ -106,7 +151,7 @@ func handleLegacyFlags() {
 			if !ok {
 				fmt.Printf("\033[31mBilinmeyen flag: %s\033[0m\n\n", arg)
 				commands.ShowHelp()
-				os.Exit(2)
+				return 2
 			}
 		}
 	}
@@ -131,10 +176,11 @@ func handleLegacyFlags() {
 
 	if *help {
 		commands.ShowHelp()
-		return
+		return 0
 	}
 
 	if err := commands.NewProject(*name, *template, *deployment, *router); err != nil {
-		os.Exit(1)
+		return 1
 	}
+	return 0
 }